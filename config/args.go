@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+)
+
+// ErrHelp is returned, wrapped in a *HelpError, when -h or --help is given.
+var ErrHelp = pflag.ErrHelp
+
+// HelpError is returned by LoadConfig when -h or --help is given, leaving it
+// to the caller to print the usage and exit.
+type HelpError struct {
+	// Usage is the rendered help message.
+	Usage string
+}
+
+// Error returns the message of ErrHelp.
+func (e *HelpError) Error() string {
+	return ErrHelp.Error()
+}
+
+// Unwrap returns ErrHelp.
+func (e *HelpError) Unwrap() error {
+	return ErrHelp
+}
+
+// ParseError is returned by LoadConfig when the command line arguments can't
+// be parsed, e.g. because of an unknown flag or an invalid flag value.
+type ParseError struct {
+	// Err is the error returned by the flag parser.
+	Err error
+
+	// Usage is the rendered help message.
+	Usage string
+}
+
+// Error returns the parsing error.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to parse flags: %v", e.Err)
+}
+
+// Unwrap returns the error returned by the flag parser.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// WithArgs sets the command line arguments to parse, without the program
+// name. Defaults to os.Args[1:].
+func WithArgs(args []string) LoadOption {
+	return func(o *loadOptions) {
+		o.args = args
+	}
+}
+
+// parseFlags parses args without printing anything, and returns a *HelpError
+// or a *ParseError holding the help message rendered by usage on failure.
+func parseFlags(fs *pflag.FlagSet, args []string, usage func(fs *pflag.FlagSet) string) error {
+	fs.SetOutput(io.Discard)
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return &HelpError{Usage: usage(fs)}
+		}
+		return &ParseError{Err: err, Usage: usage(fs)}
+	}
+
+	return nil
+}
+
+// flagSetUsage renders the default help message of fs.
+func flagSetUsage(fs *pflag.FlagSet) string {
+	return fmt.Sprintf("Usage of %s:\n%s", fs.Name(), fs.FlagUsages())
+}
+
+// programName returns the name of the running program.
+func programName() string {
+	if len(os.Args) == 0 {
+		return "config"
+	}
+	return filepath.Base(os.Args[0])
+}
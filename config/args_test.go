@@ -0,0 +1,111 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestConfigLoader_LoadConfig_WithArgs(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		os.Args = originalArgs
+	}()
+	os.Args = []string{"testapp", "--port", "1000"}
+
+	var port int
+	cfg, err := NewConfigLoader().LoadConfig(
+		[]LoadOption{WithArgs([]string{"--app-name", "args", "--port", "2000"})},
+		func(fs *pflag.FlagSet) {
+			fs.IntVar(&port, "port", 80, "Port")
+		},
+	)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if port != 2000 {
+		t.Errorf("port = %v, want 2000", port)
+	}
+	if cfg.AppName != "args" {
+		t.Errorf("AppName = %v, want args", cfg.AppName)
+	}
+}
+
+func TestConfigLoader_LoadConfig_ArgsErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantHelp  bool
+		wantError string
+	}{
+		{
+			name:     "help",
+			args:     []string{"--help"},
+			wantHelp: true,
+		},
+		{
+			name:     "shorthand help",
+			args:     []string{"-h"},
+			wantHelp: true,
+		},
+		{
+			name:      "unknown flag",
+			args:      []string{"--unknown"},
+			wantError: "failed to parse flags: unknown flag: --unknown",
+		},
+		{
+			name:      "invalid value",
+			args:      []string{"--port", "abc"},
+			wantError: `failed to parse flags: invalid argument "abc" for "--port" flag`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConfigLoader().LoadConfig(
+				[]LoadOption{WithArgs(tt.args)},
+				func(fs *pflag.FlagSet) {
+					fs.Int("port", 80, "Port")
+				},
+			)
+			if err == nil {
+				t.Fatal("LoadConfig() expected error")
+			}
+
+			if got := errors.Is(err, ErrHelp); got != tt.wantHelp {
+				t.Errorf("errors.Is(err, ErrHelp) = %v, want %v", got, tt.wantHelp)
+			}
+
+			var usage string
+			if tt.wantHelp {
+				var helpErr *HelpError
+				if !errors.As(err, &helpErr) {
+					t.Fatalf("LoadConfig() error = %T, want *HelpError", err)
+				}
+				usage = helpErr.Usage
+			} else {
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("LoadConfig() error = %T, want *ParseError", err)
+				}
+				if !strings.HasPrefix(err.Error(), tt.wantError) {
+					t.Errorf("LoadConfig() error = %v, want prefix %v", err, tt.wantError)
+				}
+				usage = parseErr.Usage
+			}
+
+			if !strings.HasPrefix(usage, "Usage of ") {
+				t.Errorf("Usage = %q, want prefix 'Usage of '", usage)
+			}
+			for _, flag := range []string{"--port", "--app-name", "--env-name"} {
+				if !strings.Contains(usage, flag) {
+					t.Errorf("Usage doesn't contain %s:\n%s", flag, usage)
+				}
+			}
+		})
+	}
+}
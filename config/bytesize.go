@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ByteSize is a size in bytes that can be set from human-readable values
+// such as "512KB", "10MiB" or "1G". It implements pflag.Value.
+type ByteSize uint64
+
+// Decimal (SI) byte size units.
+const (
+	KB ByteSize = 1000
+	MB          = 1000 * KB
+	GB          = 1000 * MB
+	TB          = 1000 * GB
+)
+
+// Binary (IEC) byte size units.
+const (
+	KiB ByteSize = 1 << (10 * (iota + 1))
+	MiB
+	GiB
+	TiB
+)
+
+var byteSizeUnits = map[string]ByteSize{
+	"":    1,
+	"b":   1,
+	"k":   KiB,
+	"kb":  KB,
+	"kib": KiB,
+	"m":   MiB,
+	"mb":  MB,
+	"mib": MiB,
+	"g":   GiB,
+	"gb":  GB,
+	"gib": GiB,
+	"t":   TiB,
+	"tb":  TB,
+	"tib": TiB,
+}
+
+// byteSizeFormats lists the units used by String, largest first.
+var byteSizeFormats = []struct {
+	unit string
+	size ByteSize
+}{
+	{"TiB", TiB}, {"TB", TB},
+	{"GiB", GiB}, {"GB", GB},
+	{"MiB", MiB}, {"MB", MB},
+	{"KiB", KiB}, {"KB", KB},
+}
+
+// ParseByteSize parses a human-readable byte size. Single-letter units
+// (K, M, G, T) are binary, "KB" style units are decimal and "KiB" style
+// units are binary. A value without a unit is a number of bytes.
+func ParseByteSize(s string) (ByteSize, error) {
+	value := strings.TrimSpace(s)
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	if i == -1 {
+		i = len(value)
+	}
+
+	number, unit := value[:i], strings.ToLower(strings.TrimSpace(value[i:]))
+	if number == "" {
+		return 0, fmt.Errorf("invalid byte size '%s'", s)
+	}
+
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size unit '%s' in '%s'", value[i:], s)
+	}
+
+	if !strings.Contains(number, ".") {
+		n, err := strconv.ParseUint(number, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid byte size '%s': %w", s, err)
+		}
+		if n > math.MaxUint64/uint64(multiplier) {
+			return 0, fmt.Errorf("invalid byte size '%s': value out of range", s)
+		}
+		return ByteSize(n) * multiplier, nil
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size '%s': %w", s, err)
+	}
+
+	// float64(math.MaxUint64) rounds up to 2^64, which doesn't fit either
+	size := f * float64(multiplier)
+	if math.IsInf(size, 0) || size >= float64(math.MaxUint64) {
+		return 0, fmt.Errorf("invalid byte size '%s': value out of range", s)
+	}
+	return ByteSize(size), nil
+}
+
+// String returns the size using the largest unit that represents it exactly.
+func (b *ByteSize) String() string {
+	for _, f := range byteSizeFormats {
+		if *b >= f.size && *b%f.size == 0 {
+			return fmt.Sprintf("%d%s", *b/f.size, f.unit)
+		}
+	}
+	return fmt.Sprintf("%dB", uint64(*b))
+}
+
+// Set parses value and stores the result.
+func (b *ByteSize) Set(value string) error {
+	size, err := ParseByteSize(value)
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// Type returns the type name used in usage messages.
+func (b *ByteSize) Type() string {
+	return "byteSize"
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ByteSize
+		wantErr  bool
+	}{
+		{input: "0", expected: 0},
+		{input: "512", expected: 512},
+		{input: "512B", expected: 512},
+		{input: "1KB", expected: 1000},
+		{input: "1KiB", expected: 1024},
+		{input: "1k", expected: 1024},
+		{input: "10MB", expected: 10 * MB},
+		{input: "10 MiB", expected: 10 * MiB},
+		{input: "1.5GiB", expected: GiB + GiB/2},
+		{input: "2TB", expected: 2 * TB},
+		{input: "", wantErr: true},
+		{input: "MB", wantErr: true},
+		{input: "10XB", wantErr: true},
+		{input: "18446744073709551615", expected: math.MaxUint64},
+		{input: "18446744073709551616", wantErr: true},
+		{input: "16777216TiB", wantErr: true},
+		{input: "20000000TB", wantErr: true},
+		{input: "16777216.0TiB", wantErr: true},
+		{input: "20000000.5TB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseByteSize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseByteSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseByteSize() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestByteSize_String(t *testing.T) {
+	tests := []struct {
+		size     ByteSize
+		expected string
+	}{
+		{size: 0, expected: "0B"},
+		{size: 100, expected: "100B"},
+		{size: 1024, expected: "1KiB"},
+		{size: 1000, expected: "1KB"},
+		{size: 10 * MiB, expected: "10MiB"},
+		{size: 10 * MB, expected: "10MB"},
+		{size: 1536, expected: "1536B"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := tt.size.String(); got != tt.expected {
+				t.Errorf("String() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+// Command golib-config manages encrypted values for config files and
+// compares the config files of environments.
+//
+// Usage:
+//
+//	golib-config keygen
+//	golib-config encrypt [--key-env NAME | --key-file PATH] [VALUE]
+//	golib-config decrypt [--key-env NAME | --key-file PATH] [VALUE]
+//	golib-config diff [--config-path DIR]... [--format text|json] ENV ENV
+//
+// When VALUE is omitted, it is read from stdin.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alexferl/golib/config"
+	"github.com/spf13/pflag"
+)
+
+const usage = `Usage:
+  golib-config keygen                   Generate a new encryption key
+  golib-config encrypt [flags] [VALUE]  Encrypt VALUE, or stdin, for a config file
+  golib-config decrypt [flags] [VALUE]  Decrypt VALUE, or stdin
+  golib-config diff [flags] ENV ENV     Compare the config files of two environments
+
+Flags:
+`
+
+func main() {
+	os.Exit(exitCode(run(os.Args[1:], os.Stdin, os.Stdout), os.Stderr))
+}
+
+// exitCode returns the exit status of the program after run returned err,
+// which is written to w unless help was requested.
+func exitCode(err error, w io.Writer) int {
+	switch {
+	case err == nil, errors.Is(err, pflag.ErrHelp):
+		return 0
+	default:
+		_, _ = fmt.Fprintln(w, "error:", err)
+		return 1
+	}
+}
+
+// run executes the command given by args.
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := pflag.NewFlagSet("golib-config", pflag.ContinueOnError)
+	keyEnv := fs.String("key-env", config.DefaultEncryptionKeyEnv, "Environment variable holding the encryption key")
+	keyFile := fs.String("key-file", "", "File holding the encryption key, takes precedence over --key-env")
+	configPaths := fs.StringSlice("config-path", []string{"./configs"}, "Directories holding the config files to compare")
+	format := fs.String("format", config.FormatText, "Format of the differences, text or json")
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(os.Stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("missing command")
+	}
+
+	command := fs.Arg(0)
+	if command == "keygen" {
+		key, err := config.GenerateKey()
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(stdout, key)
+		return err
+	}
+
+	if command == "diff" {
+		return diff(fs.Args()[1:], *configPaths, *format, stdout)
+	}
+
+	if command != "encrypt" && command != "decrypt" {
+		fs.Usage()
+		return fmt.Errorf("unknown command '%s'", command)
+	}
+
+	key, err := loadKey(*keyEnv, *keyFile)
+	if err != nil {
+		return err
+	}
+
+	value, err := readValue(fs.Args()[1:], stdin)
+	if err != nil {
+		return err
+	}
+
+	var result string
+	if command == "encrypt" {
+		result, err = config.Encrypt(key, value)
+	} else {
+		result, err = config.Decrypt(key, value)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(stdout, result)
+	return err
+}
+
+// diff prints the differences between the config files of the two
+// environments given as arguments.
+func diff(args, configPaths []string, format string, stdout io.Writer) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected two environments, got %d arguments", len(args))
+	}
+
+	loader := config.NewConfigLoader(config.WithConfigPaths(configPaths...))
+	diffs, err := loader.Diff(args[0], args[1], nil)
+	if err != nil {
+		return err
+	}
+	return config.PrintDiff(stdout, args[0], args[1], diffs, format)
+}
+
+// loadKey reads the encryption key from keyFile if set, from keyEnv otherwise.
+func loadKey(keyEnv, keyFile string) ([]byte, error) {
+	if keyFile != "" {
+		b, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+		return config.ParseKey(string(b))
+	}
+
+	value, ok := os.LookupEnv(keyEnv)
+	if !ok {
+		return nil, fmt.Errorf("%w: environment variable '%s' is not set", config.ErrNoEncryptionKey, keyEnv)
+	}
+	return config.ParseKey(value)
+}
+
+// readValue returns the value given as argument, or read from stdin.
+func readValue(args []string, stdin io.Reader) (string, error) {
+	if len(args) > 0 {
+		return strings.Join(args, " "), nil
+	}
+
+	b, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
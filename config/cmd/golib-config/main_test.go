@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexferl/golib/config"
+	"github.com/spf13/pflag"
+)
+
+func TestRun(t *testing.T) {
+	var out bytes.Buffer
+	if err := run([]string{"keygen"}, nil, &out); err != nil {
+		t.Fatalf("keygen error = %v", err)
+	}
+
+	key := strings.TrimSpace(out.String())
+	if _, err := config.ParseKey(key); err != nil {
+		t.Fatalf("keygen generated an invalid key: %v", err)
+	}
+	t.Setenv("TEST_CONFIG_KEY", key)
+
+	out.Reset()
+	if err := run([]string{"encrypt", "--key-env", "TEST_CONFIG_KEY", "s3cr3t"}, nil, &out); err != nil {
+		t.Fatalf("encrypt error = %v", err)
+	}
+
+	encrypted := strings.TrimSpace(out.String())
+	if !strings.HasPrefix(encrypted, config.EncryptedPrefix) {
+		t.Fatalf("encrypt output = %v, want prefix %v", encrypted, config.EncryptedPrefix)
+	}
+
+	out.Reset()
+	stdin := strings.NewReader(encrypted + "\n")
+	if err := run([]string{"decrypt", "--key-env", "TEST_CONFIG_KEY"}, stdin, &out); err != nil {
+		t.Fatalf("decrypt error = %v", err)
+	}
+
+	if got := strings.TrimSpace(out.String()); got != "s3cr3t" {
+		t.Errorf("decrypt output = %v, want s3cr3t", got)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		errContain string
+	}{
+		{name: "missing command", args: nil, errContain: "missing command"},
+		{name: "unknown command", args: []string{"rotate"}, errContain: "unknown command 'rotate'"},
+		{name: "missing key", args: []string{"encrypt", "--key-env", "UNSET_CONFIG_KEY", "value"}, errContain: "no encryption key"},
+		{name: "missing key file", args: []string{"encrypt", "--key-file", "/nonexistent", "value"}, errContain: "failed to read key file"},
+		{name: "diff one environment", args: []string{"diff", "dev"}, errContain: "expected two environments"},
+		{name: "diff missing config file", args: []string{"diff", "--config-path", "/nonexistent", "dev", "prod"}, errContain: "config file not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := run(tt.args, strings.NewReader(""), &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tt.errContain) {
+				t.Errorf("run() error = %v, want to contain %v", err, tt.errContain)
+			}
+		})
+	}
+}
+
+func TestRun_Diff(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.dev.toml":  "port = 8080\nsession-secret = \"dev\"\ndebug = true\n",
+		"config.prod.toml": "port = 9090\nsession-secret = \"prod\"\nsession-cookie-secret = \"enc:v1:abc\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"diff", "--config-path", dir, "dev", "prod"}, nil, &out); err != nil {
+		t.Fatalf("diff error = %v", err)
+	}
+
+	want := "" +
+		"  KEY                    dev         prod\n" +
+		"- debug                  true        (unset)\n" +
+		"~ port                   8080        9090\n" +
+		"+ session-cookie-secret  (unset)     [REDACTED]\n" +
+		"~ session-secret         [REDACTED]  [REDACTED]\n"
+	if got := out.String(); got != want {
+		t.Errorf("diff output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    int
+		wantOut string
+	}{
+		{name: "success", args: []string{"keygen"}, want: 0},
+		{name: "help", args: []string{"encrypt", "--help"}, want: 0},
+		{name: "error", args: []string{"rotate"}, want: 1, wantOut: "error: unknown command 'rotate'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			if got := exitCode(run(tt.args, nil, &bytes.Buffer{}), &stderr); got != tt.want {
+				t.Errorf("exitCode() = %v, want %v", got, tt.want)
+			}
+			if !strings.Contains(stderr.String(), tt.wantOut) || (tt.wantOut == "" && stderr.Len() > 0) {
+				t.Errorf("stderr = %q, want %q", stderr.String(), tt.wantOut)
+			}
+		})
+	}
+
+	if got := exitCode(errors.Join(errors.New("parse"), pflag.ErrHelp), &bytes.Buffer{}); got != 0 {
+		t.Errorf("exitCode() = %v, want 0 for a wrapped ErrHelp", got)
+	}
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	// ErrUnknownCommand is returned, wrapped in a *ParseError, when the
+	// command line names a command that doesn't exist.
+	ErrUnknownCommand = errors.New("unknown command")
+
+	// ErrMissingCommand is returned, wrapped in a *ParseError, when the
+	// command line doesn't name a command and the selected one can't run
+	// by itself.
+	ErrMissingCommand = errors.New("missing command")
+)
+
+// Command is a command of the program, such as "serve" or "migrate", run by
+// ConfigLoader.Execute. Commands form a tree whose root is the program.
+type Command struct {
+	// Name is the name of the command on the command line. The name of the
+	// root command defaults to the name of the program.
+	Name string
+
+	// Usage is a short description of the command shown in help messages.
+	Usage string
+
+	// Flags adds the flags of the command.
+	Flags func(fs *pflag.FlagSet)
+
+	// PersistentFlags adds the flags of the command that are also accepted
+	// by all of its subcommands, such as --log-level.
+	PersistentFlags func(fs *pflag.FlagSet)
+
+	// Options are the load options used when the command runs, e.g. the
+	// validators of its flags.
+	Options []LoadOption
+
+	// Run runs the command with the loaded configuration and the remaining
+	// positional arguments. A command without Run requires a subcommand.
+	Run func(config *Config, args []string) error
+
+	// Commands are the subcommands of the command.
+	Commands []*Command
+}
+
+// Execute selects the command of root named by the command line arguments,
+// loads the configuration with its flags and the persistent flags of its
+// parents, as LoadConfig does, and runs it. Flags of a command must come
+// after its name, while persistent flags may come anywhere after the command
+// defining them. Help is returned as a *HelpError, and unknown or missing
+// commands as a *ParseError, both holding the help message of the command.
+func (cl *ConfigLoader) Execute(root *Command, options ...LoadOption) error {
+	opts := &loadOptions{args: os.Args[1:]}
+	for _, option := range options {
+		option(opts)
+	}
+
+	path, args, err := cl.findCommand(root, opts)
+	if err != nil {
+		return err
+	}
+	cmd := path[len(path)-1]
+
+	options = append(slices.Clone(options), cmd.Options...)
+	options = append(options, WithArgs(args), func(o *loadOptions) {
+		o.usage = func(fs *pflag.FlagSet) string {
+			return commandUsage(path, fs)
+		}
+	})
+
+	config, err := cl.LoadConfig(options, commandFlags(path)...)
+	if err != nil {
+		return err
+	}
+
+	cl.mu.Lock()
+	fs := cl.flagSet
+	cl.mu.Unlock()
+
+	return cmd.Run(config, fs.Args())
+}
+
+// findCommand returns the path from root to the command named by the
+// arguments, and the arguments without the command names. Flags are parsed
+// with a shadow flag set, so the values given on the command line are only
+// set by LoadConfig. The flags of the commands are still added to find them,
+// which sets the variables bound to them to their defaults, as LoadConfig
+// does before parsing. Invalid flags are left for LoadConfig to report with
+// the right help message.
+func (cl *ConfigLoader) findCommand(root *Command, opts *loadOptions) ([]*Command, []string, error) {
+	path := []*Command{root}
+	args := slices.Clone(opts.args)
+	offset := 0
+
+	for {
+		cmd := path[len(path)-1]
+		fs := cl.newFlagSet(&Config{}, opts, cl.configurables(true), commandFlags(path))
+
+		shadow := shadowFlagSet(fs)
+		shadow.SetInterspersed(false)
+		shadow.SetOutput(io.Discard)
+		if err := shadow.Parse(args[offset:]); err != nil {
+			return path, args, nil
+		}
+
+		rest := shadow.Args()
+		index := len(args) - len(rest)
+		if len(rest) == 0 || index > 0 && args[index-1] == "--" {
+			if cmd.Run == nil {
+				return nil, nil, &ParseError{Err: ErrMissingCommand, Usage: commandUsage(path, fs)}
+			}
+			return path, args, nil
+		}
+
+		i := slices.IndexFunc(cmd.Commands, func(c *Command) bool {
+			return c.Name == rest[0]
+		})
+		if i < 0 {
+			if cmd.Run == nil {
+				return nil, nil, &ParseError{
+					Err:   fmt.Errorf("%w '%s'", ErrUnknownCommand, rest[0]),
+					Usage: commandUsage(path, fs),
+				}
+			}
+			return path, args, nil
+		}
+
+		path = append(path, cmd.Commands[i])
+		args = slices.Delete(args, index, index+1)
+		offset = index
+	}
+}
+
+// commandFlags returns the functions adding the flags of the last command of
+// path: the persistent flags of every command of path, then its own flags.
+func commandFlags(path []*Command) []func(fs *pflag.FlagSet) {
+	var flagSets []func(fs *pflag.FlagSet)
+	for _, cmd := range path {
+		if cmd.PersistentFlags != nil {
+			flagSets = append(flagSets, cmd.PersistentFlags)
+		}
+	}
+
+	if cmd := path[len(path)-1]; cmd.Flags != nil {
+		flagSets = append(flagSets, cmd.Flags)
+	}
+
+	return flagSets
+}
+
+// commandUsage renders the help message of the last command of path, whose
+// flags are fs.
+func commandUsage(path []*Command, fs *pflag.FlagSet) string {
+	names := make([]string, len(path))
+	for i, cmd := range path {
+		names[i] = cmd.Name
+	}
+	names[0] = cmp.Or(names[0], programName())
+
+	cmd := path[len(path)-1]
+
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "Usage:\n  %s", strings.Join(names, " "))
+	if len(cmd.Commands) > 0 {
+		b.WriteString(" [command]")
+	}
+	b.WriteString(" [flags]\n")
+
+	if cmd.Usage != "" {
+		_, _ = fmt.Fprintf(&b, "\n%s\n", cmd.Usage)
+	}
+
+	if len(cmd.Commands) > 0 {
+		b.WriteString("\nCommands:\n")
+		tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		for _, sub := range cmd.Commands {
+			_, _ = fmt.Fprintf(tw, "  %s\t%s\n", sub.Name, sub.Usage)
+		}
+		_ = tw.Flush()
+	}
+
+	_, _ = fmt.Fprintf(&b, "\nFlags:\n%s", fs.FlagUsages())
+
+	return b.String()
+}
+
+// shadowValue accepts any value, to parse arguments without setting flags.
+type shadowValue string
+
+func (v *shadowValue) String() string   { return "" }
+func (v *shadowValue) Set(string) error { return nil }
+func (v *shadowValue) Type() string     { return string(*v) }
+
+// shadowFlagSet returns a flag set accepting the same arguments as fs whose
+// flags aren't bound to anything.
+func shadowFlagSet(fs *pflag.FlagSet) *pflag.FlagSet {
+	shadow := pflag.NewFlagSet(fs.Name(), pflag.ContinueOnError)
+	fs.VisitAll(func(f *pflag.Flag) {
+		typ := shadowValue(f.Value.Type())
+		shadow.AddFlag(&pflag.Flag{
+			Name:        f.Name,
+			Shorthand:   f.Shorthand,
+			Value:       &typ,
+			NoOptDefVal: f.NoOptDefVal,
+		})
+	})
+	shadow.SetNormalizeFunc(fs.GetNormalizeFunc())
+	return shadow
+}
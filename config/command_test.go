@@ -0,0 +1,287 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// commandTest records what the commands of its tree were run with.
+type commandTest struct {
+	ran      string
+	args     []string
+	config   *Config
+	logLevel string
+	port     int
+	steps    int
+	dryRun   bool
+}
+
+func (ct *commandTest) run(name string) func(config *Config, args []string) error {
+	return func(config *Config, args []string) error {
+		ct.ran = name
+		ct.args = args
+		ct.config = config
+		return nil
+	}
+}
+
+func (ct *commandTest) root() *Command {
+	return &Command{
+		Name:  "myapp",
+		Usage: "My application",
+		PersistentFlags: func(fs *pflag.FlagSet) {
+			fs.StringVar(&ct.logLevel, "log-level", "INFO", "Log level")
+		},
+		Commands: []*Command{
+			{
+				Name:  "serve",
+				Usage: "Start the server",
+				Flags: func(fs *pflag.FlagSet) {
+					fs.IntVar(&ct.port, "port", 8080, "Port")
+				},
+				Run: ct.run("serve"),
+			},
+			{
+				Name:  "migrate",
+				Usage: "Migrate the database",
+				PersistentFlags: func(fs *pflag.FlagSet) {
+					fs.BoolVar(&ct.dryRun, "dry-run", false, "Show the migrations without running them")
+				},
+				Run: ct.run("migrate"),
+				Commands: []*Command{
+					{
+						Name:  "down",
+						Usage: "Revert migrations",
+						Flags: func(fs *pflag.FlagSet) {
+							fs.IntVar(&ct.steps, "steps", 1, "Number of migrations to revert")
+						},
+						Run: ct.run("migrate down"),
+					},
+				},
+			},
+			{
+				Name:  "config",
+				Usage: "Manage the configuration",
+				Commands: []*Command{
+					{Name: "validate", Usage: "Validate the configuration", Run: ct.run("config validate")},
+				},
+			},
+		},
+	}
+}
+
+func TestConfigLoader_Execute(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		want     commandTest
+		wantEnv  string
+		wantArgs []string
+	}{
+		{
+			name: "command flags",
+			args: []string{"serve", "--port", "9090"},
+			want: commandTest{ran: "serve", logLevel: "INFO", port: 9090, steps: 0},
+		},
+		{
+			name:    "persistent flags before and after the command",
+			args:    []string{"--log-level", "DEBUG", "--env-name", "prod", "serve", "--port=9090"},
+			want:    commandTest{ran: "serve", logLevel: "DEBUG", port: 9090},
+			wantEnv: "prod",
+		},
+		{
+			name: "environment",
+			args: []string{"serve"},
+			env:  map[string]string{"MYAPP_PORT": "7070", "MYAPP_LOG_LEVEL": "WARN"},
+			want: commandTest{ran: "serve", logLevel: "WARN", port: 7070},
+		},
+		{
+			name: "nested command",
+			args: []string{"migrate", "--dry-run", "down", "--steps", "3"},
+			want: commandTest{ran: "migrate down", logLevel: "INFO", dryRun: true, steps: 3},
+		},
+		{
+			name:     "command with positional arguments",
+			args:     []string{"migrate", "up", "--dry-run"},
+			want:     commandTest{ran: "migrate", logLevel: "INFO", dryRun: true},
+			wantArgs: []string{"up"},
+		},
+		{
+			name:     "arguments after terminator",
+			args:     []string{"migrate", "--", "down"},
+			want:     commandTest{ran: "migrate", logLevel: "INFO"},
+			wantArgs: []string{"down"},
+		},
+		{
+			name: "group command",
+			args: []string{"config", "validate"},
+			want: commandTest{ran: "config validate", logLevel: "INFO"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetenv(t, "MYAPP_PORT", "MYAPP_LOG_LEVEL")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			ct := &commandTest{}
+			loader := NewConfigLoader(WithEnvPrefix("MYAPP"))
+			if err := loader.Execute(ct.root(), WithArgs(tt.args)); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if ct.ran != tt.want.ran {
+				t.Errorf("ran = %v, want %v", ct.ran, tt.want.ran)
+			}
+			if ct.logLevel != tt.want.logLevel {
+				t.Errorf("log-level = %v, want %v", ct.logLevel, tt.want.logLevel)
+			}
+			if ct.port != tt.want.port {
+				t.Errorf("port = %v, want %v", ct.port, tt.want.port)
+			}
+			if ct.steps != tt.want.steps {
+				t.Errorf("steps = %v, want %v", ct.steps, tt.want.steps)
+			}
+			if ct.dryRun != tt.want.dryRun {
+				t.Errorf("dry-run = %v, want %v", ct.dryRun, tt.want.dryRun)
+			}
+			if len(ct.args) > 0 || len(tt.wantArgs) > 0 {
+				if !reflect.DeepEqual(ct.args, tt.wantArgs) {
+					t.Errorf("args = %v, want %v", ct.args, tt.wantArgs)
+				}
+			}
+
+			wantEnv := tt.wantEnv
+			if wantEnv == "" {
+				wantEnv = DefaultEnvName
+			}
+			if ct.config.EnvName != wantEnv {
+				t.Errorf("EnvName = %v, want %v", ct.config.EnvName, wantEnv)
+			}
+		})
+	}
+}
+
+func TestConfigLoader_Execute_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantErr   error
+		wantHelp  bool
+		wantUsage []string
+		notUsage  []string
+	}{
+		{
+			name:      "root help",
+			args:      []string{"--help"},
+			wantErr:   ErrHelp,
+			wantHelp:  true,
+			wantUsage: []string{"myapp [command] [flags]", "My application", "serve    Start the server", "--log-level"},
+			notUsage:  []string{"--port"},
+		},
+		{
+			name:      "command help",
+			args:      []string{"migrate", "down", "-h"},
+			wantErr:   ErrHelp,
+			wantHelp:  true,
+			wantUsage: []string{"myapp migrate down [flags]", "Revert migrations", "--steps", "--dry-run", "--log-level"},
+			notUsage:  []string{"Commands:", "--port"},
+		},
+		{
+			name:      "missing command",
+			wantErr:   ErrMissingCommand,
+			wantUsage: []string{"Commands:"},
+		},
+		{
+			name:      "unknown command",
+			args:      []string{"deploy"},
+			wantErr:   ErrUnknownCommand,
+			wantUsage: []string{"Commands:"},
+		},
+		{
+			name:      "unknown nested command",
+			args:      []string{"config", "check"},
+			wantErr:   ErrUnknownCommand,
+			wantUsage: []string{"myapp config [command] [flags]", "validate"},
+		},
+		{
+			name:      "command flag before the command",
+			args:      []string{"--port", "9090", "serve"},
+			wantUsage: []string{"myapp [command] [flags]"},
+		},
+		{
+			name:      "invalid flag value",
+			args:      []string{"serve", "--port", "abc"},
+			wantUsage: []string{"myapp serve [flags]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ct := &commandTest{}
+			err := NewConfigLoader().Execute(ct.root(), WithArgs(tt.args))
+			if err == nil {
+				t.Fatal("Execute() expected error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+			if ct.ran != "" {
+				t.Errorf("ran %v, want no command to run", ct.ran)
+			}
+
+			var usage string
+			var helpErr *HelpError
+			var parseErr *ParseError
+			switch {
+			case errors.As(err, &helpErr):
+				usage = helpErr.Usage
+			case errors.As(err, &parseErr):
+				usage = parseErr.Usage
+			default:
+				t.Fatalf("Execute() error = %T, want *HelpError or *ParseError", err)
+			}
+			if (helpErr != nil) != tt.wantHelp {
+				t.Errorf("Execute() error = %T, want help %v", err, tt.wantHelp)
+			}
+
+			for _, s := range tt.wantUsage {
+				if !strings.Contains(usage, s) {
+					t.Errorf("usage doesn't contain %q:\n%s", s, usage)
+				}
+			}
+			for _, s := range tt.notUsage {
+				if strings.Contains(usage, s) {
+					t.Errorf("usage contains %q:\n%s", s, usage)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigLoader_Execute_Options(t *testing.T) {
+	validationErr := errors.New("invalid")
+	root := &Command{
+		Commands: []*Command{
+			{
+				Name:    "serve",
+				Options: []LoadOption{WithValidators(func() error { return validationErr })},
+				Run: func(*Config, []string) error {
+					return nil
+				},
+			},
+		},
+	}
+
+	err := NewConfigLoader().Execute(root, WithArgs([]string{"serve"}))
+	if !errors.Is(err, validationErr) {
+		t.Errorf("Execute() error = %v, want %v", err, validationErr)
+	}
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Configurable is a component configured by flags, such as server.Config,
+// logger.Config or middleware.CORS. The name of its flag set, e.g. "Server",
+// names the component in validation errors.
+type Configurable interface {
+	FlagSet() *pflag.FlagSet
+}
+
+// Validator is implemented by components that validate their configuration.
+type Validator interface {
+	Validate() error
+}
+
+// ComponentError holds the validation errors of a registered component.
+type ComponentError struct {
+	Component string
+	Err       error
+}
+
+// Error returns the errors of the component, one per line, e.g.
+// "Server: 'server-http-bind-addr' is required".
+func (e *ComponentError) Error() string {
+	return e.Component + ": " + strings.ReplaceAll(e.Err.Error(), "\n", "\n"+e.Component+": ")
+}
+
+// Unwrap returns the validation errors.
+func (e *ComponentError) Unwrap() error {
+	return e.Err
+}
+
+// component is a registered Configurable.
+type component struct {
+	name         string
+	configurable Configurable
+}
+
+// Register adds components whose flags are added to every flag set the
+// loader parses, and which are validated, if they implement Validator or
+// Constrained, after every load and reload. Their errors are returned as *ComponentError, so
+// they're grouped by component.
+func (cl *ConfigLoader) Register(components ...Configurable) *ConfigLoader {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	for _, c := range components {
+		cl.components = append(cl.components, component{
+			name:         c.FlagSet().Name(),
+			configurable: c,
+		})
+	}
+	return cl
+}
+
+// configurables returns the registered components, or copies of them when
+// detached is set, so that setting the flags of a flag set that isn't the one
+// loaded, such as the flag set of Diff, doesn't write the live components.
+func (cl *ConfigLoader) configurables(detached bool) []Configurable {
+	components := cl.registered()
+	configurables := make([]Configurable, len(components))
+	for i, c := range components {
+		configurables[i] = c.configurable
+		if detached {
+			configurables[i] = detach(c.configurable)
+		}
+	}
+	return configurables
+}
+
+// detach returns a shallow copy of c, when it's a pointer to a struct as
+// components are. The flag set of the copy is bound to the copy, and holds
+// the current values of c as defaults.
+func detach(c Configurable) Configurable {
+	v := reflect.ValueOf(c)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return c
+	}
+
+	cp := reflect.New(v.Elem().Type())
+	cp.Elem().Set(v.Elem())
+	return cp.Interface().(Configurable)
+}
+
+// registered returns the registered components.
+func (cl *ConfigLoader) registered() []component {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	return cl.components
+}
+
+// validateComponents returns the validation errors and the violated
+// constraints of components, checked against the values of fs.
+func validateComponents(fs *pflag.FlagSet, components []component) error {
+	var errs []error
+	for _, c := range components {
+		var componentErrs []error
+		if v, ok := c.configurable.(Validator); ok {
+			componentErrs = append(componentErrs, v.Validate())
+		}
+		if v, ok := c.configurable.(Constrained); ok {
+			componentErrs = append(componentErrs, checkConstraints(fs, v.Constraints()))
+		}
+
+		if err := errors.Join(componentErrs...); err != nil {
+			errs = append(errs, &ComponentError{Component: c.name, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
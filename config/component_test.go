@@ -0,0 +1,139 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// testComponent is a Configurable and Validator with a single flag.
+type testComponent struct {
+	name string
+	key  string
+	Port int `validate:"min=1"`
+}
+
+func (c *testComponent) FlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet(c.name, pflag.ContinueOnError)
+	fs.IntVar(&c.Port, c.key, c.Port, "Port")
+	return fs
+}
+
+func (c *testComponent) Validate() error {
+	return ValidateComponent(c)
+}
+
+// flagsOnly is a Configurable without validation.
+type flagsOnly struct {
+	Name string
+}
+
+func (c *flagsOnly) FlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("Flags Only", pflag.ContinueOnError)
+	fs.StringVar(&c.Name, "flags-only-name", c.Name, "Name")
+	return fs
+}
+
+func TestConfigLoader_Register(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		wantServer     int
+		wantAdmin      int
+		wantComponents []string
+	}{
+		{
+			name:       "defaults",
+			wantServer: 8080,
+			wantAdmin:  9090,
+		},
+		{
+			name:       "flags",
+			args:       []string{"--server-port", "1", "--admin-port", "2", "--flags-only-name", "x"},
+			wantServer: 1,
+			wantAdmin:  2,
+		},
+		{
+			name:           "invalid component",
+			args:           []string{"--server-port", "0"},
+			wantComponents: []string{"Server"},
+		},
+		{
+			name:           "every invalid component",
+			args:           []string{"--server-port", "0", "--admin-port", "-1"},
+			wantComponents: []string{"Server", "Admin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &testComponent{name: "Server", key: "server-port", Port: 8080}
+			admin := &testComponent{name: "Admin", key: "admin-port", Port: 9090}
+			other := &flagsOnly{}
+
+			loader := NewConfigLoader().Register(server, admin, other)
+			_, err := loader.LoadConfig([]LoadOption{WithArgs(tt.args)})
+
+			if len(tt.wantComponents) == 0 {
+				if err != nil {
+					t.Fatalf("LoadConfig() error = %v", err)
+				}
+				if server.Port != tt.wantServer || admin.Port != tt.wantAdmin {
+					t.Errorf("ports = %v, %v, want %v, %v", server.Port, admin.Port, tt.wantServer, tt.wantAdmin)
+				}
+				return
+			}
+
+			var got []string
+			for _, e := range componentErrors(err) {
+				got = append(got, e.Component)
+			}
+			if len(got) != len(tt.wantComponents) {
+				t.Fatalf("LoadConfig() error = %v, want errors of %v", err, tt.wantComponents)
+			}
+			for i := range got {
+				if got[i] != tt.wantComponents[i] {
+					t.Errorf("component %d = %v, want %v", i, got[i], tt.wantComponents[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConfigLoader_Register_FlagSet(t *testing.T) {
+	loader := NewConfigLoader().Register(&flagsOnly{})
+	if loader.FlagSet(nil).Lookup("flags-only-name") == nil {
+		t.Error("FlagSet() is missing the flags of the registered component")
+	}
+}
+
+func TestComponentError_Error(t *testing.T) {
+	err := &ComponentError{
+		Component: "Server",
+		Err:       errors.Join(errors.New("'a' is required"), errors.New("'b' must be at least 1, got 0")),
+	}
+
+	want := "Server: 'a' is required\nServer: 'b' must be at least 1, got 0"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+// componentErrors returns the *ComponentError joined or wrapped in err.
+func componentErrors(err error) []*ComponentError {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *ComponentError:
+		return []*ComponentError{e}
+	case interface{ Unwrap() []error }:
+		var errs []*ComponentError
+		for _, joined := range e.Unwrap() {
+			errs = append(errs, componentErrors(joined)...)
+		}
+		return errs
+	default:
+		return componentErrors(errors.Unwrap(err))
+	}
+}
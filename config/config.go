@@ -1,11 +1,20 @@
 package config
 
 import (
+	"encoding/csv"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"reflect"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/spf13/cast"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
@@ -19,8 +28,8 @@ const (
 
 // Config holds all global configuration for the application.
 type Config struct {
-	AppName string
-	EnvName string
+	AppName string `validate:"required"`
+	EnvName string `validate:"required"`
 }
 
 // ConfigLoader manages the configuration loading process.
@@ -28,6 +37,36 @@ type ConfigLoader struct {
 	envVarPrefix string
 	configPaths  []string
 	configType   string
+	stdout       io.Writer
+	providers    []Provider
+	pollInterval time.Duration
+	version      string
+
+	deprecationHandler DeprecationHandler
+
+	// reloadMu serializes reloads, which set the flags outside of mu.
+	reloadMu sync.Mutex
+
+	mu           sync.Mutex
+	viper        *viper.Viper
+	files        []configFile
+	flagSet      *pflag.FlagSet
+	config       *Config
+	opts         *loadOptions
+	defaults     map[string]any
+	resolvers    map[string]Resolver
+	secrets      map[string]bool
+	flagSecrets  map[string]bool
+	subscribers  []ChangeFunc
+	onError      []func(error)
+	watcher      *watcher
+	deprecations []Deprecation
+	components   []component
+
+	// dotenv holds the variables of the .env files read last. It has its own
+	// lock since it's set by readConfig, which runs outside of mu.
+	dotenvMu sync.RWMutex
+	dotenv   map[string]dotenvVar
 }
 
 // Option defines a function type for configuring ConfigLoader.
@@ -40,6 +79,15 @@ type LoadOption func(*loadOptions)
 type loadOptions struct {
 	loadConfigFile bool
 	configFileName string
+	watch          bool
+	printConfig    bool
+	dotenv         bool
+	strict         bool
+	validateConfig bool
+	args           []string
+	usage          func(fs *pflag.FlagSet) string
+	validators     []func() error
+	constraints    []Constraint
 }
 
 // WithEnvPrefix sets the environment variable prefix.
@@ -56,7 +104,8 @@ func WithConfigPaths(paths ...string) Option {
 	}
 }
 
-// WithConfigType sets the configuration file type.
+// WithConfigType restricts config files to a single type, e.g. "yaml".
+// By default, config files of any of ConfigTypes are found.
 func WithConfigType(configType string) Option {
 	return func(cl *ConfigLoader) {
 		cl.configType = configType
@@ -78,13 +127,32 @@ func WithCustomConfigFile(fileName string) LoadOption {
 	}
 }
 
+// WithWatch enables reloading the configuration when the config file or a
+// provider changes, or the process receives SIGHUP. See ConfigLoader.Reload.
+func WithWatch(enabled bool) LoadOption {
+	return func(o *loadOptions) {
+		o.watch = enabled
+	}
+}
+
+// WithValidators adds validation functions that run after the configuration
+// is loaded and after every reload. A reload is rejected if any of them fails.
+func WithValidators(validators ...func() error) LoadOption {
+	return func(o *loadOptions) {
+		o.validators = append(o.validators, validators...)
+	}
+}
+
 // NewConfigLoader creates a new ConfigLoader with the given options.
 func NewConfigLoader(options ...Option) *ConfigLoader {
 	cl := &ConfigLoader{
 		envVarPrefix: "",
 		configPaths:  []string{"./configs", "/configs"},
-		configType:   "toml",
+		viper:        viper.New(),
+		stdout:       os.Stdout,
+		pollInterval: DefaultPollInterval,
 	}
+	cl.resolvers = defaultResolvers(cl.lookupEnv)
 
 	for _, option := range options {
 		option(cl)
@@ -93,6 +161,14 @@ func NewConfigLoader(options ...Option) *ConfigLoader {
 	return cl
 }
 
+// Viper returns the viper instance owned by the loader. It holds the values
+// resolved by the last call to LoadConfig or Reload.
+func (cl *ConfigLoader) Viper() *viper.Viper {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	return cl.viper
+}
+
 // AddConfigPath adds a configuration file search path.
 func (cl *ConfigLoader) AddConfigPath(path string) *ConfigLoader {
 	cl.configPaths = append(cl.configPaths, path)
@@ -101,13 +177,7 @@ func (cl *ConfigLoader) AddConfigPath(path string) *ConfigLoader {
 
 // Validate checks if the configuration values are valid.
 func (c *Config) Validate() error {
-	if strings.TrimSpace(c.AppName) == "" {
-		return errors.New("application name cannot be empty")
-	}
-	if strings.TrimSpace(c.EnvName) == "" {
-		return errors.New("environment name cannot be empty")
-	}
-	return nil
+	return ValidateStruct(c, nil)
 }
 
 // bindFlags adds all the flags to the provided flag set.
@@ -126,90 +196,467 @@ func normalizeFlags(f *pflag.FlagSet, name string) pflag.NormalizedName {
 }
 
 // setupViper configures viper with environment variable settings.
-func (cl *ConfigLoader) setupViper() {
+func (cl *ConfigLoader) setupViper(v *viper.Viper) {
 	if cl.envVarPrefix != "" {
-		viper.SetEnvPrefix(cl.envVarPrefix)
+		v.SetEnvPrefix(cl.envVarPrefix)
 	}
 	replacer := strings.NewReplacer("-", "_")
-	viper.SetEnvKeyReplacer(replacer)
-	viper.AutomaticEnv()
+	v.SetEnvKeyReplacer(replacer)
+	v.AutomaticEnv()
+}
+
+// bindEnvs binds flags annotated with explicit environment variable names,
+// and the environment variables of deprecated names.
+func (cl *ConfigLoader) bindEnvs(v *viper.Viper, fs *pflag.FlagSet) error {
+	var errs []error
+	fs.VisitAll(func(f *pflag.Flag) {
+		_, explicit := f.Annotations[envAnnotation]
+		aliases := cl.aliasEnvs(f)
+		if explicit || len(aliases) > 0 {
+			envs := append(cl.envNames(f), aliases...)
+			errs = append(errs, v.BindEnv(append([]string{f.Name}, envs...)...))
+		}
+	})
+	return errors.Join(errs...)
 }
 
-// loadConfigFile loads the configuration file using viper.
-func (cl *ConfigLoader) loadConfigFile(configName string) error {
-	viper.SetConfigName(configName)
-	viper.SetConfigType(cl.configType)
+// readConfig returns a new viper instance holding the values of the parsed
+// flags, the environment, including .env files, the providers and, if
+// enabled, the config files, along with the config files and providers that
+// were read. References to keys and environment variables in the values of
+// config files are expanded, see interpolator.
+func (cl *ConfigLoader) readConfig(fs *pflag.FlagSet, opts *loadOptions) (*viper.Viper, []configFile, error) {
+	v := viper.New()
+
+	if err := v.BindPFlags(fs); err != nil {
+		return nil, nil, fmt.Errorf("failed to bind flags to viper: %w", err)
+	}
+
+	if err := cl.bindEnvs(v, fs); err != nil {
+		return nil, nil, fmt.Errorf("failed to bind environment variables: %w", err)
+	}
+
+	cl.setupViper(v)
 
-	for _, path := range cl.configPaths {
-		viper.AddConfigPath(path)
+	if opts.dotenv {
+		if err := cl.loadDotenv(v, fs); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	if err := viper.ReadInConfig(); err != nil {
-		var configFileNotFoundError viper.ConfigFileNotFoundError
-		if errors.As(err, &configFileNotFoundError) {
-			return fmt.Errorf("config file '%s.%s' not found in paths %v: %w",
-				configName, cl.configType, cl.configPaths, err)
+	var files []configFile
+	if opts.loadConfigFile {
+		var err error
+		files, err = cl.readConfigFiles(configLayers(v, opts))
+		if err != nil {
+			return nil, nil, err
 		}
-		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	return nil
+	remote, err := cl.readProviders()
+	if err != nil {
+		return nil, nil, err
+	}
+	files = append(files, remote...)
+
+	aliases := flagAliases(fs)
+	for i := range files {
+		files[i].aliases = renameAliases(files[i].values, aliases)
+	}
+
+	if err := cl.interpolate(fs, files); err != nil {
+		return nil, nil, err
+	}
+
+	for _, file := range files {
+		if err := v.MergeConfigMap(file.values); err != nil {
+			return nil, nil, fmt.Errorf("failed to merge values of '%s': %w", file.path, err)
+		}
+	}
+
+	return v, files, nil
 }
 
-// LoadConfig loads configuration with the specified options.
+// LoadConfig loads configuration with the specified options. It never exits
+// the process: a *HelpError is returned when help is requested, a *ParseError
+// when the arguments are invalid, ErrConfigPrinted after --print-config and
+// ErrConfigValid after a successful --validate-config.
 func (cl *ConfigLoader) LoadConfig(options []LoadOption, flagSets ...func(fs *pflag.FlagSet)) (*Config, error) {
 	opts := &loadOptions{
 		loadConfigFile: false,
 		configFileName: "",
+		args:           os.Args[1:],
+		usage:          flagSetUsage,
 	}
 
 	for _, option := range options {
 		option(opts)
 	}
 
-	fs := pflag.NewFlagSet("config", pflag.ExitOnError)
-
 	config := &Config{
 		AppName: DefaultAppName,
 		EnvName: DefaultEnvName,
 	}
 
+	fs := cl.newFlagSet(config, opts, cl.configurables(false), flagSets)
+	defaults := snapshotFlags(fs)
+
+	if err := parseFlags(fs, opts.args, opts.usage); err != nil {
+		return nil, err
+	}
+
+	if validating, _ := fs.GetBool(ValidateConfig); opts.validateConfig && validating {
+		return nil, cl.dryRun(fs, config, opts, defaults)
+	}
+
+	flagSecrets, err := cl.resolveFlagReferences(fs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply configuration values: %w", err)
+	}
+
+	v, files, err := cl.readConfig(fs, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.strict {
+		if err := cl.checkUnknownKeys(fs, files); err != nil {
+			return nil, err
+		}
+	}
+
+	deprecations, err := cl.collectDeprecations(fs, opts.args, files)
+	if err != nil {
+		return nil, err
+	}
+	cl.reportDeprecations(deprecations, nil)
+
+	secrets, err := cl.applyFlagValues(v, fs, files, defaults)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply configuration values: %w", err)
+	}
+	maps.Copy(secrets, flagSecrets)
+
+	if format, _ := fs.GetString(PrintConfig); opts.printConfig && format != "" {
+		if err := printEntries(cl.stdout, cl.effective(fs, v, files, secrets), format); err != nil {
+			return nil, fmt.Errorf("failed to print config: %w", err)
+		}
+		return nil, ErrConfigPrinted
+	}
+
+	if err := validate(fs, config, opts, cl.registered()); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	cl.mu.Lock()
+	cl.viper = v
+	cl.files = files
+	cl.secrets = secrets
+	cl.flagSecrets = flagSecrets
+	cl.flagSet = fs
+	cl.config = config
+	cl.opts = opts
+	cl.defaults = defaults
+	cl.deprecations = deprecations
+	cl.mu.Unlock()
+
+	if opts.watch {
+		if err := cl.watch(); err != nil {
+			return nil, fmt.Errorf("failed to watch config: %w", err)
+		}
+	}
+
+	return config, nil
+}
+
+// FlagSet returns the flag set LoadConfig parses with the same arguments,
+// holding the default values, without loading anything. It is meant to
+// generate documentation, see JSONSchema and ConfigLoader.Markdown.
+//
+// The flags are bound to copies. Until a configuration is loaded, calling
+// flagSets sets the variables they bind to their defaults, as LoadConfig
+// does. Once it's loaded, flagSets is ignored and the flags LoadConfig parsed
+// are copied instead, so the loaded values are kept.
+func (cl *ConfigLoader) FlagSet(options []LoadOption, flagSets ...func(fs *pflag.FlagSet)) *pflag.FlagSet {
+	opts := &loadOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+
+	return cl.defaultFlagSet(opts, flagSets)
+}
+
+// defaultFlagSet returns the flag set of FlagSet and Diff, whose flags are
+// bound to copies of the registered components and of the variables of the
+// program. Flags copied from the flag set LoadConfig parsed hold the loaded
+// values, so they're set back to the defaults it recorded.
+func (cl *ConfigLoader) defaultFlagSet(opts *loadOptions, flagSets []func(fs *pflag.FlagSet)) *pflag.FlagSet {
+	cl.mu.Lock()
+	loaded, defaults := cl.flagSet, cl.defaults
+	cl.mu.Unlock()
+
+	config := &Config{AppName: DefaultAppName, EnvName: DefaultEnvName}
+	var fs *pflag.FlagSet
+	if loaded == nil {
+		fs = cl.newFlagSet(config, opts, cl.configurables(true), flagSets)
+		fs.VisitAll(func(f *pflag.Flag) {
+			f.Value = detachValue(f.Value)
+		})
+	} else {
+		fs = cl.newFlagSet(config, opts, cl.configurables(true), nil)
+		loaded.VisitAll(func(f *pflag.Flag) {
+			if _, ok := f.Annotations[internalAnnotation]; ok || fs.Lookup(f.Name) != nil {
+				return
+			}
+			cp := *f
+			cp.Value = detachValue(f.Value)
+			cp.Changed = false
+			fs.AddFlag(&cp)
+		})
+		fs.SetNormalizeFunc(aliasNormalizer(fs, normalizeFlags))
+	}
+
+	for name, value := range defaults {
+		f := fs.Lookup(name)
+		if f == nil || reflect.DeepEqual(flagValue(f), value) {
+			continue
+		}
+		// the defaults were taken from the same flags, so they're valid
+		if err := setFlagValue(f, value); err == nil {
+			f.DefValue = f.Value.String()
+		}
+	}
+
+	return fs
+}
+
+// newFlagSet returns a flag set holding the global flags bound to config,
+// the flags of components, the flags controlling the loader and the flags
+// added by flagSets.
+func (cl *ConfigLoader) newFlagSet(config *Config, opts *loadOptions, components []Configurable, flagSets []func(fs *pflag.FlagSet)) *pflag.FlagSet {
+	fs := pflag.NewFlagSet(programName(), pflag.ContinueOnError)
+
 	cl.bindFlags(fs, config)
+	for _, c := range components {
+		fs.AddFlagSet(c.FlagSet())
+	}
+
+	if opts.validateConfig {
+		fs.Bool(ValidateConfig, false, "Validate the configuration and exit")
+		_ = fs.SetAnnotation(ValidateConfig, internalAnnotation, nil)
+	}
+
+	if opts.printConfig {
+		fs.String(PrintConfig, "",
+			fmt.Sprintf("Print the resolved configuration and exit\nValues: %s", strings.Join(printFormats, ", ")))
+		fs.Lookup(PrintConfig).NoOptDefVal = FormatText
+		_ = fs.SetAnnotation(PrintConfig, internalAnnotation, nil)
+	}
 
 	for _, flagSet := range flagSets {
 		flagSet(fs)
 	}
 
-	fs.SetNormalizeFunc(normalizeFlags)
+	fs.SetNormalizeFunc(aliasNormalizer(fs, normalizeFlags))
+
+	return fs
+}
+
+// validate runs the global configuration validation, validators and the
+// validation of components, and returns all of their errors.
+func validate(fs *pflag.FlagSet, config *Config, opts *loadOptions, components []component) error {
+	var errs []error
+	if err := config.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, validator := range opts.validators {
+		if err := validator(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 
-	if err := fs.Parse(os.Args[1:]); err != nil {
-		return nil, fmt.Errorf("failed to parse flags: %w", err)
+	if err := checkConstraints(fs, opts.constraints); err != nil {
+		errs = append(errs, err)
 	}
 
-	if err := viper.BindPFlags(fs); err != nil {
-		return nil, fmt.Errorf("failed to bind flags to viper: %w", err)
+	if err := validateComponents(fs, components); err != nil {
+		errs = append(errs, err)
 	}
 
-	cl.setupViper()
+	return errors.Join(errs...)
+}
 
-	if opts.loadConfigFile {
-		configName := opts.configFileName
-		if configName == "" {
-			envName := viper.GetString(EnvName)
-			configName = fmt.Sprintf("config.%s", strings.ToLower(envName))
+// applyFlagValues sets every flag that was not given on the command line to the
+// value viper resolved from the environment or the config file, or back to its
+// default when neither sets it anymore. This writes the value through to the
+// variable bound by the flag, so every registered FlagSet honours the
+// file < env < flag precedence. It returns the keys whose value was resolved
+// from a reference, along with the keys set by secret providers.
+func (cl *ConfigLoader) applyFlagValues(v *viper.Viper, fs *pflag.FlagSet, files []configFile, defaults map[string]any) (map[string]bool, error) {
+	secrets := make(map[string]bool)
+	var errs []error
+
+	for _, file := range files {
+		if !file.secret {
+			continue
+		}
+		for key := range file.values {
+			secrets[key] = true
 		}
+	}
 
-		if err := cl.loadConfigFile(configName); err != nil {
-			return nil, err
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			return
+		}
+
+		var err error
+		if v.IsSet(f.Name) {
+			var value any
+			var secret bool
+			value, secret, err = cl.resolveValue(v.Get(f.Name))
+			if err == nil {
+				secrets[f.Name] = secrets[f.Name] || secret
+				err = setFlagValue(f, value)
+			}
+		} else if def, ok := defaults[f.Name]; ok && !reflect.DeepEqual(flagValue(f), def) {
+			err = setFlagValue(f, def)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid value for '%s': %w", f.Name, err))
+		}
+	})
+
+	return secrets, errors.Join(errs...)
+}
+
+// MapValue is a flag value set from a table of a config file or provider,
+// e.g. [features] in TOML, as is, rather than from the "key=value" pairs
+// given to Set for maps. Set is still used for the command line and
+// environment variables, and to restore the value returned by String.
+type MapValue interface {
+	pflag.Value
+	SetMap(m map[string]any) error
+}
+
+// setFlagValue sets the flag value from a value resolved by viper.
+func setFlagValue(f *pflag.Flag, value any) error {
+	if mv, ok := f.Value.(MapValue); ok {
+		if m, ok := value.(map[string]any); ok {
+			return mv.SetMap(m)
 		}
 	}
 
-	config.AppName = viper.GetString(AppName)
-	config.EnvName = viper.GetString(EnvName)
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		values, err := toStringSlice(value)
+		if err != nil {
+			return err
+		}
+		return sv.Replace(values)
+	}
 
-	if err := config.Validate(); err != nil {
-		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	if m, ok := value.(map[string]any); ok {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		pairs := make([]string, 0, len(m))
+		for _, k := range keys {
+			pairs = append(pairs, fmt.Sprintf("%s=%v", k, m[k]))
+		}
+		return f.Value.Set(strings.Join(pairs, ","))
 	}
 
-	return config, nil
+	s, err := cast.ToStringE(value)
+	if err != nil {
+		return err
+	}
+	return f.Value.Set(s)
+}
+
+// flagValue returns the current value of the flag in a form accepted by
+// setFlagValue: a []string for slices and a string for everything else.
+func flagValue(f *pflag.Flag) any {
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		return slices.Clone(sv.GetSlice())
+	}
+
+	s := f.Value.String()
+	if strings.HasPrefix(f.Value.Type(), "stringTo") {
+		s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	}
+	return s
+}
+
+// pflagValues adds a flag named "value" of the types of pflag that are
+// structs pointing to the variable they're bound to, by type name.
+var pflagValues = map[string]func(fs *pflag.FlagSet){
+	"stringSlice":    func(fs *pflag.FlagSet) { fs.StringSlice("value", nil, "") },
+	"stringArray":    func(fs *pflag.FlagSet) { fs.StringArray("value", nil, "") },
+	"intSlice":       func(fs *pflag.FlagSet) { fs.IntSlice("value", nil, "") },
+	"int32Slice":     func(fs *pflag.FlagSet) { fs.Int32Slice("value", nil, "") },
+	"int64Slice":     func(fs *pflag.FlagSet) { fs.Int64Slice("value", nil, "") },
+	"uintSlice":      func(fs *pflag.FlagSet) { fs.UintSlice("value", nil, "") },
+	"float32Slice":   func(fs *pflag.FlagSet) { fs.Float32Slice("value", nil, "") },
+	"float64Slice":   func(fs *pflag.FlagSet) { fs.Float64Slice("value", nil, "") },
+	"boolSlice":      func(fs *pflag.FlagSet) { fs.BoolSlice("value", nil, "") },
+	"durationSlice":  func(fs *pflag.FlagSet) { fs.DurationSlice("value", nil, "") },
+	"ipSlice":        func(fs *pflag.FlagSet) { fs.IPSlice("value", nil, "") },
+	"stringToString": func(fs *pflag.FlagSet) { fs.StringToString("value", nil, "") },
+	"stringToInt":    func(fs *pflag.FlagSet) { fs.StringToInt("value", nil, "") },
+	"stringToInt64":  func(fs *pflag.FlagSet) { fs.StringToInt64("value", nil, "") },
+}
+
+// detachValue returns a copy of value that isn't bound to the same variable.
+// Values of other types than those of pflag are copied like components by
+// detach, so the copy is shallow.
+func detachValue(value pflag.Value) pflag.Value {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return value
+	}
+
+	if add, ok := pflagValues[value.Type()]; ok && v.Elem().Type().PkgPath() == reflect.TypeFor[pflag.Flag]().PkgPath() {
+		fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+		add(fs)
+		f := fs.Lookup("value")
+		// the value was taken from a flag of the same type, so it's valid
+		_ = setFlagValue(f, flagValue(&pflag.Flag{Value: value}))
+		return f.Value
+	}
+
+	cp := reflect.New(v.Elem().Type())
+	cp.Elem().Set(v.Elem())
+	if dv, ok := cp.Interface().(pflag.Value); ok {
+		return dv
+	}
+	return value
+}
+
+// snapshotFlags returns the current value of every flag.
+func snapshotFlags(fs *pflag.FlagSet) map[string]any {
+	values := make(map[string]any)
+	fs.VisitAll(func(f *pflag.Flag) {
+		values[f.Name] = flagValue(f)
+	})
+	return values
+}
+
+// toStringSlice converts a list from a config file or a comma-separated
+// environment variable to a slice of strings.
+func toStringSlice(value any) ([]string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return cast.ToStringSliceE(value)
+	}
+
+	if s == "" {
+		return []string{}, nil
+	}
+
+	return csv.NewReader(strings.NewReader(s)).Read()
 }
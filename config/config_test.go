@@ -1,9 +1,12 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -21,7 +24,7 @@ func TestNewConfigLoader(t *testing.T) {
 			expected: &ConfigLoader{
 				envVarPrefix: "",
 				configPaths:  []string{"./configs", "/configs"},
-				configType:   "toml",
+				configType:   "",
 			},
 		},
 		{
@@ -32,7 +35,7 @@ func TestNewConfigLoader(t *testing.T) {
 			expected: &ConfigLoader{
 				envVarPrefix: "MYAPP",
 				configPaths:  []string{"./configs", "/configs"},
-				configType:   "toml",
+				configType:   "",
 			},
 		},
 		{
@@ -43,7 +46,7 @@ func TestNewConfigLoader(t *testing.T) {
 			expected: &ConfigLoader{
 				envVarPrefix: "",
 				configPaths:  []string{"./custom", "/etc/app"},
-				configType:   "toml",
+				configType:   "",
 			},
 		},
 		{
@@ -95,11 +98,6 @@ func TestNewConfigLoader(t *testing.T) {
 }
 
 func TestConfigLoader_LoadConfig(t *testing.T) {
-	// Reset viper for each test
-	defer func() {
-		viper.Reset()
-	}()
-
 	// Save original args and restore after test
 	originalArgs := os.Args
 	defer func() {
@@ -179,29 +177,26 @@ func TestConfigLoader_LoadConfig(t *testing.T) {
 			args:       []string{"testapp"},
 			loadOpts:   []LoadOption{WithConfigFile(true)},
 			wantErr:    true,
-			errContain: "Not Found", // Updated to match actual Viper error
+			errContain: "config file not found",
 		},
 		{
 			name:       "load with custom config file",
 			args:       []string{"testapp"},
 			loadOpts:   []LoadOption{WithCustomConfigFile("custom-config")},
 			wantErr:    true,
-			errContain: "Not Found", // Updated to match actual Viper error
+			errContain: "config file not found",
 		},
 		{
 			name:       "validation error - empty app name",
 			args:       []string{"testapp", "--app-name", ""},
 			loadOpts:   nil,
 			wantErr:    true,
-			errContain: "application name cannot be empty",
+			errContain: "'app-name' is required",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Reset viper for each test
-			viper.Reset()
-
 			for key, value := range tt.envVars {
 				err := os.Setenv(key, value)
 				if err != nil {
@@ -268,7 +263,7 @@ func TestConfig_Validate(t *testing.T) {
 				EnvName: "prod",
 			},
 			wantErr: true,
-			errMsg:  "application name cannot be empty",
+			errMsg:  "'app-name' is required",
 		},
 		{
 			name: "whitespace app name",
@@ -277,7 +272,7 @@ func TestConfig_Validate(t *testing.T) {
 				EnvName: "prod",
 			},
 			wantErr: true,
-			errMsg:  "application name cannot be empty",
+			errMsg:  "'app-name' is required",
 		},
 		{
 			name: "empty env name",
@@ -286,7 +281,7 @@ func TestConfig_Validate(t *testing.T) {
 				EnvName: "",
 			},
 			wantErr: true,
-			errMsg:  "environment name cannot be empty",
+			errMsg:  "'env-name' is required",
 		},
 		{
 			name: "whitespace env name",
@@ -295,7 +290,7 @@ func TestConfig_Validate(t *testing.T) {
 				EnvName: "   ",
 			},
 			wantErr: true,
-			errMsg:  "environment name cannot be empty",
+			errMsg:  "'env-name' is required",
 		},
 	}
 
@@ -444,3 +439,211 @@ func TestLoadOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigLoader_LoadConfig_FlagTargets(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		os.Args = originalArgs
+	}()
+
+	dir := t.TempDir()
+	content := `bind-addr = "file:8080"
+read-timeout = "5s"
+origins = ["https://a.example.com", "https://b.example.com"]
+level = "debug"
+retries = 3
+`
+	if err := os.WriteFile(filepath.Join(dir, "config.test.toml"), []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		envVars  map[string]string
+		validate func(t *testing.T, c *targets)
+	}{
+		{
+			name: "values from config file",
+			args: []string{"testapp", "--env-name", "test"},
+			validate: func(t *testing.T, c *targets) {
+				if c.bindAddr != "file:8080" {
+					t.Errorf("bindAddr = %v, want file:8080", c.bindAddr)
+				}
+				if c.readTimeout != 5*time.Second {
+					t.Errorf("readTimeout = %v, want 5s", c.readTimeout)
+				}
+				if len(c.origins) != 2 || c.origins[1] != "https://b.example.com" {
+					t.Errorf("origins = %v, want 2 origins from file", c.origins)
+				}
+				if c.level != "debug" {
+					t.Errorf("level = %v, want debug", c.level)
+				}
+				if c.retries != 3 {
+					t.Errorf("retries = %v, want 3", c.retries)
+				}
+			},
+		},
+		{
+			name: "env overrides config file",
+			args: []string{"testapp", "--env-name", "test"},
+			envVars: map[string]string{
+				"TEST_BIND_ADDR": "env:8080",
+				"TEST_ORIGINS":   "https://c.example.com,https://d.example.com",
+			},
+			validate: func(t *testing.T, c *targets) {
+				if c.bindAddr != "env:8080" {
+					t.Errorf("bindAddr = %v, want env:8080", c.bindAddr)
+				}
+				if len(c.origins) != 2 || c.origins[0] != "https://c.example.com" {
+					t.Errorf("origins = %v, want origins from env", c.origins)
+				}
+			},
+		},
+		{
+			name: "flag overrides env and config file",
+			args: []string{"testapp", "--env-name", "test", "--bind-addr", "flag:8080", "--retries", "5"},
+			envVars: map[string]string{
+				"TEST_BIND_ADDR": "env:8080",
+			},
+			validate: func(t *testing.T, c *targets) {
+				if c.bindAddr != "flag:8080" {
+					t.Errorf("bindAddr = %v, want flag:8080", c.bindAddr)
+				}
+				if c.retries != 5 {
+					t.Errorf("retries = %v, want 5", c.retries)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.envVars {
+				t.Setenv(key, value)
+			}
+
+			os.Args = tt.args
+
+			c := &targets{}
+			loader := NewConfigLoader(WithEnvPrefix("TEST"), WithConfigPaths(dir))
+			_, err := loader.LoadConfig([]LoadOption{WithConfigFile(true)}, c.flagSet)
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			tt.validate(t, c)
+		})
+	}
+}
+
+type targets struct {
+	bindAddr    string
+	readTimeout time.Duration
+	origins     []string
+	level       string
+	retries     int
+}
+
+func (c *targets) flagSet(fs *pflag.FlagSet) {
+	fs.StringVar(&c.bindAddr, "bind-addr", "localhost:8080", "Bind address")
+	fs.DurationVar(&c.readTimeout, "read-timeout", 10*time.Second, "Read timeout")
+	fs.StringSliceVar(&c.origins, "origins", []string{"*"}, "Allowed origins")
+	fs.StringVar(&c.level, "level", "info", "Log level")
+	fs.IntVar(&c.retries, "retries", 1, "Retries")
+}
+
+// jsonMap is a MapValue holding a JSON object.
+type jsonMap map[string]any
+
+func (m *jsonMap) String() string {
+	b, _ := json.Marshal(*m)
+	return string(b)
+}
+
+func (m *jsonMap) Set(s string) error {
+	*m = jsonMap{}
+	return json.Unmarshal([]byte(s), m)
+}
+
+func (m *jsonMap) SetMap(values map[string]any) error {
+	*m = values
+	return nil
+}
+
+func (m *jsonMap) Type() string {
+	return "json"
+}
+
+func TestConfigLoader_LoadConfig_MapValue(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "config.local.toml", "[limits]\nusers = 10\n\n[limits.admin]\nenabled = true\n")
+
+	tests := []struct {
+		name string
+		env  string
+		want string
+	}{
+		{name: "table of config file", want: `{"admin":{"enabled":true},"users":10}`},
+		{name: "env overrides config file", env: `{"users":20}`, want: `{"users":20}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetenv(t, "LIMITS")
+			if tt.env != "" {
+				t.Setenv("LIMITS", tt.env)
+			}
+
+			limits := jsonMap{}
+			loader := NewConfigLoader(WithConfigPaths(dir))
+			_, err := loader.LoadConfig([]LoadOption{WithArgs(nil), WithConfigFile(true)}, func(fs *pflag.FlagSet) {
+				fs.Var(&limits, "limits", "Limits")
+			})
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			if got := limits.String(); got != tt.want {
+				t.Errorf("limits = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigLoader_IsolatedViper(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		os.Args = originalArgs
+	}()
+
+	t.Setenv("FIRST_APP_NAME", "first")
+	t.Setenv("SECOND_APP_NAME", "second")
+	os.Args = []string{"testapp"}
+
+	first := NewConfigLoader(WithEnvPrefix("FIRST"))
+	second := NewConfigLoader(WithEnvPrefix("SECOND"))
+
+	firstConfig, err := first.LoadConfig(nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	secondConfig, err := second.LoadConfig(nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if firstConfig.AppName != "first" {
+		t.Errorf("first AppName = %v, want first", firstConfig.AppName)
+	}
+	if secondConfig.AppName != "second" {
+		t.Errorf("second AppName = %v, want second", secondConfig.AppName)
+	}
+	if first.Viper() == second.Viper() {
+		t.Error("loaders share the same viper instance")
+	}
+	if viper.IsSet(AppName) {
+		t.Error("global viper instance was modified")
+	}
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Constraint is a rule on a key that only applies under conditions on other
+// keys, such as a certificate file required when TLS is enabled. Constraints
+// are checked by ConfigLoader against the merged configuration, after config
+// files, providers, environment variables and flags were applied.
+type Constraint struct {
+	// Key is the key checked, e.g. "server-tls-cert-file".
+	Key string
+
+	// Rule is checked against the value of Key, with the syntax of a single
+	// rule of a `validate` tag, e.g. "required", "min=1" or "ne=changeme".
+	Rule string
+
+	// When lists the conditions that must all hold for the rule to apply.
+	// The rule always applies when it's empty.
+	When []Condition
+}
+
+// Condition compares the value of a key, as shown by --print-config, with a
+// value. Values are compared case-insensitively.
+type Condition struct {
+	Key   string
+	Value string
+
+	// Not inverts the condition, so it holds when the value differs.
+	Not bool
+}
+
+// If returns a Condition holding when the value of key is value, e.g.
+// If("server-tls-enabled", "true").
+func If(key, value string) Condition {
+	return Condition{Key: key, Value: value}
+}
+
+// Unless returns a Condition holding when the value of key isn't value.
+func Unless(key, value string) Condition {
+	return Condition{Key: key, Value: value, Not: true}
+}
+
+// String returns the condition, e.g. "'env-name' is 'prod'".
+func (c Condition) String() string {
+	if c.Not {
+		return fmt.Sprintf("'%s' is not '%s'", c.Key, c.Value)
+	}
+	return fmt.Sprintf("'%s' is '%s'", c.Key, c.Value)
+}
+
+// Constrained is implemented by components declaring constraints involving
+// several of their keys, or keys of the loader such as EnvName. They're
+// checked for registered components after every load and reload.
+type Constrained interface {
+	Constraints() []Constraint
+}
+
+// WithConstraints adds constraints checked after the configuration is loaded
+// and after every reload. Every violated constraint is reported, and a reload
+// is rejected if any of them is.
+func WithConstraints(constraints ...Constraint) LoadOption {
+	return func(o *loadOptions) {
+		o.constraints = append(o.constraints, constraints...)
+	}
+}
+
+// checkConstraints checks constraints against the values of fs and returns a
+// *ValidationError listing every violation.
+func checkConstraints(fs *pflag.FlagSet, constraints []Constraint) error {
+	var errs []*FieldError
+	for _, c := range constraints {
+		if err := checkConstraint(fs, c); err != nil {
+			errs = append(errs, &FieldError{Key: c.Key, Rule: c.Rule, Err: err})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// checkConstraint returns the violation of c, if any.
+func checkConstraint(fs *pflag.FlagSet, c Constraint) error {
+	f := fs.Lookup(c.Key)
+	if f == nil {
+		return fmt.Errorf("has constraint '%s' but isn't a flag", c.Rule)
+	}
+
+	conditions := make([]string, len(c.When))
+	for i, cond := range c.When {
+		cf := fs.Lookup(cond.Key)
+		if cf == nil {
+			return fmt.Errorf("has constraint '%s' depending on '%s', which isn't a flag", c.Rule, cond.Key)
+		}
+		if strings.EqualFold(formatFlagValue(typedFlagValue(cf)), cond.Value) == cond.Not {
+			return nil
+		}
+		conditions[i] = cond.String()
+	}
+
+	err := checkRule(reflect.ValueOf(constraintValue(f)), strings.TrimSpace(c.Rule), nil)
+	if err == nil || len(conditions) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w when %s", err, strings.Join(conditions, " and "))
+}
+
+// constraintValue returns the value of f that rules are checked against.
+// Durations are parsed so that rules such as "min=1s" compare them as
+// time.Duration, as they do on struct fields.
+func constraintValue(f *pflag.Flag) any {
+	switch f.Value.Type() {
+	case "duration":
+		if d, err := time.ParseDuration(f.Value.String()); err == nil {
+			return d
+		}
+	case "durationSlice":
+		values := f.Value.(pflag.SliceValue).GetSlice()
+		durations := make([]time.Duration, 0, len(values))
+		for _, s := range values {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return values
+			}
+			durations = append(durations, d)
+		}
+		return durations
+	}
+	return typedFlagValue(f)
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+var testConstraints = []Constraint{
+	{Key: "cert-file", Rule: "required", When: []Condition{If("tls-enabled", "true"), Unless("acme-enabled", "true")}},
+	{Key: "acme-hosts", Rule: "required", When: []Condition{If("acme-enabled", "true")}},
+	{Key: "secret", Rule: "ne=changeme", When: []Condition{If(EnvName, "prod")}},
+}
+
+func constraintFlags(fs *pflag.FlagSet) {
+	fs.Bool("tls-enabled", false, "Enable TLS")
+	fs.Bool("acme-enabled", false, "Enable ACME")
+	fs.String("cert-file", "", "Certificate file")
+	fs.StringSlice("acme-hosts", nil, "ACME hosts")
+	fs.String("secret", "changeme", "Secret")
+}
+
+func TestConfigLoader_LoadConfig_Constraints(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr []string
+	}{
+		{
+			name: "conditions not met",
+		},
+		{
+			name:    "required when",
+			args:    []string{"--tls-enabled"},
+			wantErr: []string{"'cert-file' is required when 'tls-enabled' is 'true' and 'acme-enabled' is not 'true'"},
+		},
+		{
+			name: "required satisfied",
+			args: []string{"--tls-enabled", "--cert-file", "cert.pem"},
+		},
+		{
+			name:    "slice required",
+			args:    []string{"--tls-enabled", "--acme-enabled"},
+			wantErr: []string{"'acme-hosts' is required when 'acme-enabled' is 'true'"},
+		},
+		{
+			name: "slice satisfied",
+			args: []string{"--tls-enabled", "--acme-enabled", "--acme-hosts", "example.com"},
+		},
+		{
+			name:    "not equal",
+			args:    []string{"--env-name", "PROD"},
+			wantErr: []string{"'secret' must not be 'changeme' when 'env-name' is 'prod'"},
+		},
+		{
+			name: "every violation",
+			args: []string{"--env-name", "prod", "--tls-enabled", "--acme-enabled"},
+			wantErr: []string{
+				"'acme-hosts' is required when 'acme-enabled' is 'true'",
+				"'secret' must not be 'changeme' when 'env-name' is 'prod'",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := NewConfigLoader()
+			_, err := loader.LoadConfig([]LoadOption{WithArgs(tt.args), WithConstraints(testConstraints...)}, constraintFlags)
+
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("LoadConfig() error = %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("LoadConfig() error = %v, want *ValidationError", err)
+			}
+			if len(validationErr.Errors) != len(tt.wantErr) {
+				t.Fatalf("LoadConfig() error = %v, want %d errors", err, len(tt.wantErr))
+			}
+			for i, want := range tt.wantErr {
+				if got := validationErr.Errors[i].Error(); got != want {
+					t.Errorf("error %d = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigLoader_LoadConfig_DurationConstraint(t *testing.T) {
+	constraint := Constraint{Key: "timeout", Rule: "min=1s", When: []Condition{If("tls-enabled", "true")}}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "conditions not met", args: []string{"--timeout", "500ms"}},
+		{name: "satisfied", args: []string{"--tls-enabled", "--timeout", "1s"}},
+		{
+			name:    "violated",
+			args:    []string{"--tls-enabled", "--timeout", "500ms"},
+			wantErr: "'timeout' must be at least 1s, got 500ms when 'tls-enabled' is 'true'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := NewConfigLoader()
+			_, err := loader.LoadConfig([]LoadOption{WithArgs(tt.args), WithConstraints(constraint)}, func(fs *pflag.FlagSet) {
+				constraintFlags(fs)
+				fs.Duration("timeout", 5*time.Second, "Timeout")
+			})
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("LoadConfig() error = %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || len(validationErr.Errors) != 1 {
+				t.Fatalf("LoadConfig() error = %v, want a *ValidationError", err)
+			}
+			if got := validationErr.Errors[0].Error(); got != tt.wantErr {
+				t.Errorf("error = %v, want %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigLoader_LoadConfig_ConstraintUnknownKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint Constraint
+		wantErr    string
+	}{
+		{
+			name:       "key",
+			constraint: Constraint{Key: "missing", Rule: "required"},
+			wantErr:    "'missing' has constraint 'required' but isn't a flag",
+		},
+		{
+			name:       "condition",
+			constraint: Constraint{Key: "secret", Rule: "required", When: []Condition{If("missing", "true")}},
+			wantErr:    "'secret' has constraint 'required' depending on 'missing', which isn't a flag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := NewConfigLoader()
+			_, err := loader.LoadConfig([]LoadOption{WithArgs(nil), WithConstraints(tt.constraint)}, constraintFlags)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfig() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// constrainedComponent is a Configurable declaring constraints.
+type constrainedComponent struct{}
+
+func (c *constrainedComponent) FlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("TLS", pflag.ContinueOnError)
+	constraintFlags(fs)
+	return fs
+}
+
+func (c *constrainedComponent) Constraints() []Constraint {
+	return testConstraints
+}
+
+func TestConfigLoader_Register_Constraints(t *testing.T) {
+	loader := NewConfigLoader().Register(&constrainedComponent{})
+
+	_, err := loader.LoadConfig([]LoadOption{WithArgs([]string{"--env-name", "prod", "--tls-enabled"})})
+	errs := componentErrors(err)
+	if len(errs) != 1 {
+		t.Fatalf("LoadConfig() error = %v, want a *ComponentError", err)
+	}
+
+	want := "TLS: 'cert-file' is required when 'tls-enabled' is 'true' and 'acme-enabled' is not 'true'\n" +
+		"TLS: 'secret' must not be 'changeme' when 'env-name' is 'prod'"
+	if got := errs[0].Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
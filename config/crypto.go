@@ -0,0 +1,170 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// EncryptedPrefix is the prefix of values encrypted with Encrypt.
+	EncryptedPrefix = "enc:v1:"
+
+	// DefaultEncryptionKeyEnv is the environment variable the golib-config
+	// command reads the encryption key from by default.
+	DefaultEncryptionKeyEnv = "CONFIG_ENCRYPTION_KEY"
+
+	// KeySize is the size in bytes of encryption keys.
+	KeySize = 32
+)
+
+// ErrNoEncryptionKey is returned when an encrypted value is found but no
+// encryption key was configured.
+var ErrNoEncryptionKey = errors.New("no encryption key configured")
+
+// GenerateKey returns a new random encryption key, encoded in base64.
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseKey decodes a base64 encoded encryption key.
+func ParseKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid encryption key: must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// Encrypt encrypts plaintext with AES-256-GCM and returns a value starting
+// with EncryptedPrefix that can be stored in config files.
+func Encrypt(key []byte, plaintext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value returned by Encrypt.
+func Decrypt(key []byte, value string) (string, error) {
+	data, ok := strings.CutPrefix(value, EncryptedPrefix)
+	if !ok {
+		return "", fmt.Errorf("encrypted value must start with '%s'", EncryptedPrefix)
+	}
+	return decrypt(key, data)
+}
+
+// decrypt decrypts the base64 encoded nonce and ciphertext.
+func decrypt(key []byte, data string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted value: %w", err)
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("invalid encrypted value: too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("failed to decrypt value: wrong key or corrupted data")
+	}
+
+	return string(plaintext), nil
+}
+
+// newGCM returns an AES-GCM cipher for key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid encryption key: must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// decrypter is a Resolver for encrypted values. The key is loaded the first
+// time a value is decrypted, so a missing key is only an error when the
+// configuration holds encrypted values.
+type decrypter struct {
+	loadKey func() ([]byte, error)
+}
+
+// Resolve decrypts the value.
+func (d *decrypter) Resolve(data string) (string, error) {
+	key, err := d.loadKey()
+	if err != nil {
+		return "", err
+	}
+	return decrypt(key, data)
+}
+
+// WithEncryptionKey sets the key used to decrypt values starting with
+// EncryptedPrefix.
+func WithEncryptionKey(key []byte) Option {
+	return withDecrypter(func() ([]byte, error) {
+		return key, nil
+	})
+}
+
+// WithEncryptionKeyEnv reads the base64 encoded key used to decrypt values
+// starting with EncryptedPrefix from the environment variable name, which
+// may also be set in the .env files.
+func WithEncryptionKeyEnv(name string) Option {
+	return func(cl *ConfigLoader) {
+		withDecrypter(func() ([]byte, error) {
+			value, ok := cl.lookupEnv(name)
+			if !ok {
+				return nil, fmt.Errorf("%w: environment variable '%s' is not set", ErrNoEncryptionKey, name)
+			}
+			return ParseKey(value)
+		})(cl)
+	}
+}
+
+// WithEncryptionKeyFile reads the base64 encoded key used to decrypt values
+// starting with EncryptedPrefix from the file at path.
+func WithEncryptionKeyFile(path string) Option {
+	return withDecrypter(func() ([]byte, error) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption key file: %w", err)
+		}
+		return ParseKey(string(b))
+	})
+}
+
+// withDecrypter registers the resolver for encrypted values.
+func withDecrypter(loadKey func() ([]byte, error)) Option {
+	return WithResolver(EncryptedPrefix, &decrypter{loadKey: loadKey})
+}
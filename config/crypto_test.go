@@ -0,0 +1,185 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func testKey(t *testing.T) (string, []byte) {
+	t.Helper()
+	encoded, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	key, err := ParseKey(encoded)
+	if err != nil {
+		t.Fatalf("ParseKey() error = %v", err)
+	}
+	return encoded, key
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	_, key := testKey(t)
+	_, otherKey := testKey(t)
+
+	encrypted, err := Encrypt(key, "s3cr3t")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	if !strings.HasPrefix(encrypted, EncryptedPrefix) {
+		t.Errorf("Encrypt() = %v, want prefix %v", encrypted, EncryptedPrefix)
+	}
+
+	decrypted, err := Decrypt(key, encrypted)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if decrypted != "s3cr3t" {
+		t.Errorf("Decrypt() = %v, want s3cr3t", decrypted)
+	}
+
+	tests := []struct {
+		name  string
+		key   []byte
+		value string
+	}{
+		{name: "wrong key", key: otherKey, value: encrypted},
+		{name: "missing prefix", key: key, value: strings.TrimPrefix(encrypted, EncryptedPrefix)},
+		{name: "tampered value", key: key, value: encrypted[:len(encrypted)-4] + "AAA="},
+		{name: "too short", key: key, value: EncryptedPrefix + "AAAA"},
+		{name: "invalid key size", key: key[:16], value: encrypted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decrypt(tt.key, tt.value); err == nil {
+				t.Error("Decrypt() expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "valid key", key: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=\n"},
+		{name: "not base64", key: "not a key", wantErr: true},
+		{name: "wrong size", key: "c2hvcnQ=", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigLoader_EncryptedValues(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		os.Args = originalArgs
+	}()
+
+	encodedKey, key := testKey(t)
+	encrypted, err := Encrypt(key, "s3cr3t")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	keyDir := t.TempDir()
+	keyFile := filepath.Join(keyDir, "key")
+	if err := os.WriteFile(keyFile, []byte(encodedKey+"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		options []Option
+		envVars map[string]string
+		dotenv  string
+		wantErr error
+	}{
+		{
+			name:    "key",
+			options: []Option{WithEncryptionKey(key)},
+		},
+		{
+			name:    "key from environment variable",
+			options: []Option{WithEncryptionKeyEnv("TEST_KEY")},
+			envVars: map[string]string{"TEST_KEY": encodedKey},
+		},
+		{
+			name:    "key from .env file",
+			options: []Option{WithEncryptionKeyEnv("TEST_KEY")},
+			dotenv:  "TEST_KEY=" + encodedKey + "\n",
+		},
+		{
+			name:    "key from file",
+			options: []Option{WithEncryptionKeyFile(keyFile)},
+		},
+		{
+			name:    "no key",
+			wantErr: ErrNoEncryptionKey,
+		},
+		{
+			name:    "unset key environment variable",
+			options: []Option{WithEncryptionKeyEnv("TEST_KEY")},
+			wantErr: ErrNoEncryptionKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeConfigFile(t, dir, "config.test.toml", `secret = "`+encrypted+`"`)
+
+			for k, v := range tt.envVars {
+				t.Setenv(k, v)
+			}
+
+			loadOpts := []LoadOption{WithConfigFile(true)}
+			if tt.dotenv != "" {
+				writeConfigFile(t, dir, DotenvFileName, tt.dotenv)
+				t.Chdir(dir)
+				loadOpts = append(loadOpts, WithDotenv(true))
+			}
+
+			os.Args = []string{"testapp", "--env-name", "test"}
+
+			var secret string
+			loader := NewConfigLoader(append([]Option{WithConfigPaths(dir)}, tt.options...)...)
+			_, err := loader.LoadConfig(loadOpts, func(fs *pflag.FlagSet) {
+				fs.StringVar(&secret, "secret", "", "Secret")
+			})
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("LoadConfig() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			if secret != "s3cr3t" {
+				t.Errorf("secret = %v, want s3cr3t", secret)
+			}
+			if !loader.IsSecret("secret") {
+				t.Error("IsSecret() = false, want true")
+			}
+		})
+	}
+}
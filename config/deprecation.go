@@ -0,0 +1,316 @@
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// aliasAnnotation prefixes the flag annotations holding a deprecated name of
+// the flag, followed by the name. The annotation holds the Since and
+// RemovedIn versions.
+const aliasAnnotation = "config_alias:"
+
+// ErrRemovedKey is returned when a deprecated key is used at or after the
+// version it was removed in.
+var ErrRemovedKey = errors.New("removed configuration key")
+
+// Alias is a deprecated name of a configuration key, such as the name it had
+// before being renamed. It keeps working as a flag, environment variable and
+// config file key until the version it's removed in.
+type Alias struct {
+	// Name is the deprecated key, e.g. "session-store-type".
+	Name string
+
+	// Since is the version the key was deprecated in. Optional.
+	Since string
+
+	// RemovedIn is the version from which using the key is an error, when
+	// the version of the application is set with WithVersion. Optional.
+	RemovedIn string
+}
+
+// Deprecation reports the use of a deprecated key.
+type Deprecation struct {
+	// Key is the key replacing the deprecated one.
+	Key string
+
+	// Alias is the deprecated key.
+	Alias Alias
+
+	// Source is where the deprecated key is used.
+	Source Source
+
+	// Origin is the flag, environment variable, file or provider using the
+	// deprecated key.
+	Origin string
+}
+
+// String describes the deprecation, e.g. "'session-store-type' is deprecated
+// since v1.2.0, use 'session-store' instead (env MYAPP_SESSION_STORE_TYPE)".
+func (d Deprecation) String() string {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "'%s' is deprecated", d.Alias.Name)
+	if d.Alias.Since != "" {
+		_, _ = fmt.Fprintf(&b, " since %s", d.Alias.Since)
+	}
+	_, _ = fmt.Fprintf(&b, ", use '%s' instead", d.Key)
+	if d.Alias.RemovedIn != "" {
+		_, _ = fmt.Fprintf(&b, ", it will be removed in %s", d.Alias.RemovedIn)
+	}
+	_, _ = fmt.Fprintf(&b, " (%s %s)", d.Source, d.Origin)
+	return b.String()
+}
+
+// Fields returns the deprecation as structured log fields, as logged by
+// logger.Logger.LogDeprecation. The versions are only set when known.
+func (d Deprecation) Fields() map[string]any {
+	fields := map[string]any{
+		"key":         d.Alias.Name,
+		"replacement": d.Key,
+		"source":      string(d.Source),
+		"origin":      d.Origin,
+	}
+	if d.Alias.Since != "" {
+		fields["deprecated_since"] = d.Alias.Since
+	}
+	if d.Alias.RemovedIn != "" {
+		fields["removed_in"] = d.Alias.RemovedIn
+	}
+	return fields
+}
+
+// AddAlias registers a deprecated name of the flag key of fs. The flag, its
+// environment variable and its config file key can be set with the
+// deprecated name, which is reported to the DeprecationHandler and by
+// ConfigLoader.Deprecations.
+func AddAlias(fs *pflag.FlagSet, key string, alias Alias) error {
+	if fs.Lookup(key) == nil {
+		return fmt.Errorf("no such flag '%s'", key)
+	}
+
+	alias.Name = string(normalizeFlags(fs, alias.Name))
+	if alias.Name == "" || fs.Lookup(alias.Name) != nil {
+		return fmt.Errorf("invalid alias '%s' of '%s': the name is already used", alias.Name, key)
+	}
+
+	return fs.SetAnnotation(key, aliasAnnotation+alias.Name, []string{alias.Since, alias.RemovedIn})
+}
+
+// DeprecationHandler is called for every deprecated key used when the
+// configuration is loaded, and for the ones newly used on reload.
+type DeprecationHandler func(d Deprecation)
+
+// WithDeprecationHandler sets the function reporting deprecated keys, e.g.
+// one passing them to logger.Logger.LogDeprecation.
+func WithDeprecationHandler(handler DeprecationHandler) Option {
+	return func(cl *ConfigLoader) {
+		cl.deprecationHandler = handler
+	}
+}
+
+// WithVersion sets the version of the application. Deprecated keys are an
+// error from the version they're removed in.
+func WithVersion(version string) Option {
+	return func(cl *ConfigLoader) {
+		cl.version = version
+	}
+}
+
+// Deprecations returns the deprecated keys used by the loaded configuration.
+func (cl *ConfigLoader) Deprecations() []Deprecation {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	return slices.Clone(cl.deprecations)
+}
+
+// flagAlias is a deprecated name of a flag.
+type flagAlias struct {
+	flag  *pflag.Flag
+	alias Alias
+}
+
+// flagAliases returns the deprecated names of the flags of fs.
+func flagAliases(fs *pflag.FlagSet) map[string]flagAlias {
+	aliases := make(map[string]flagAlias)
+	fs.VisitAll(func(f *pflag.Flag) {
+		for _, alias := range aliasesOf(f) {
+			aliases[alias.Name] = flagAlias{flag: f, alias: alias}
+		}
+	})
+	return aliases
+}
+
+// aliasesOf returns the deprecated names of the flag, sorted by name.
+func aliasesOf(f *pflag.Flag) []Alias {
+	var aliases []Alias
+	for annotation, versions := range f.Annotations {
+		name, ok := strings.CutPrefix(annotation, aliasAnnotation)
+		if !ok || len(versions) != 2 {
+			continue
+		}
+		aliases = append(aliases, Alias{Name: name, Since: versions[0], RemovedIn: versions[1]})
+	}
+	slices.SortFunc(aliases, func(a, b Alias) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return aliases
+}
+
+// aliasNormalizer wraps normalize to map the deprecated flag names of fs to
+// the flags they're an alias of.
+func aliasNormalizer(fs *pflag.FlagSet, normalize func(*pflag.FlagSet, string) pflag.NormalizedName) func(*pflag.FlagSet, string) pflag.NormalizedName {
+	aliases := flagAliases(fs)
+	if len(aliases) == 0 {
+		return normalize
+	}
+
+	return func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		normalized := normalize(f, name)
+		if a, ok := aliases[string(normalized)]; ok {
+			return pflag.NormalizedName(a.flag.Name)
+		}
+		return normalized
+	}
+}
+
+// renameAliases moves the values of deprecated keys to the keys they're an
+// alias of, unless those are set too, and returns the deprecated keys found.
+func renameAliases(values map[string]any, aliases map[string]flagAlias) []string {
+	var found []string
+	for name, a := range aliases {
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		if _, ok := values[a.flag.Name]; !ok {
+			values[a.flag.Name] = value
+		}
+		delete(values, name)
+		found = append(found, name)
+	}
+	slices.Sort(found)
+	return found
+}
+
+// collectDeprecations returns the deprecated keys used by the arguments, the
+// environment and the config files and providers. It fails if any of them
+// was removed in the version of the application.
+func (cl *ConfigLoader) collectDeprecations(fs *pflag.FlagSet, args []string, files []configFile) ([]Deprecation, error) {
+	aliases := flagAliases(fs)
+	if len(aliases) == 0 {
+		return nil, nil
+	}
+
+	var deprecations []Deprecation
+	add := func(name string, source Source, origin string) {
+		a := aliases[name]
+		deprecations = append(deprecations, Deprecation{
+			Key:    a.flag.Name,
+			Alias:  a.alias,
+			Source: source,
+			Origin: origin,
+		})
+	}
+
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, ok := strings.CutPrefix(arg, "--")
+		if !ok {
+			continue
+		}
+		name, _, _ = strings.Cut(name, "=")
+		if name = string(normalizeFlags(fs, name)); aliases[name].flag != nil {
+			add(name, SourceFlag, "--"+name)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(aliases)) {
+		env := cl.envName(name)
+		if value, ok := cl.lookupEnv(env); ok && value != "" {
+			add(name, SourceEnv, env)
+		}
+	}
+
+	for _, file := range files {
+		for _, name := range file.aliases {
+			add(name, file.source, file.path)
+		}
+	}
+
+	var errs []error
+	for _, d := range deprecations {
+		if cl.version != "" && d.Alias.RemovedIn != "" && compareVersions(cl.version, d.Alias.RemovedIn) >= 0 {
+			errs = append(errs, fmt.Errorf("%w: '%s' was removed in %s, use '%s' instead (%s %s)",
+				ErrRemovedKey, d.Alias.Name, d.Alias.RemovedIn, d.Key, d.Source, d.Origin))
+		}
+	}
+
+	return deprecations, errors.Join(errs...)
+}
+
+// reportDeprecations calls the DeprecationHandler with the deprecations that
+// aren't in previous.
+func (cl *ConfigLoader) reportDeprecations(deprecations, previous []Deprecation) {
+	if cl.deprecationHandler == nil {
+		return
+	}
+
+	for _, d := range deprecations {
+		if !slices.Contains(previous, d) {
+			cl.deprecationHandler(d)
+		}
+	}
+}
+
+// aliasEnvs returns the environment variables of the deprecated names of
+// the flag.
+func (cl *ConfigLoader) aliasEnvs(f *pflag.Flag) []string {
+	var envs []string
+	for _, alias := range aliasesOf(f) {
+		envs = append(envs, cl.envName(alias.Name))
+	}
+	return envs
+}
+
+// compareVersions compares two versions such as "v1.2.3", ignoring
+// pre-release and build metadata, and returns -1, 0 or 1.
+func compareVersions(a, b string) int {
+	parse := func(v string) []string {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+		if i := strings.IndexAny(v, "-+"); i >= 0 {
+			v = v[:i]
+		}
+		return strings.Split(v, ".")
+	}
+
+	pa, pb := parse(a), parse(b)
+	for i := range max(len(pa), len(pb)) {
+		var sa, sb string
+		if i < len(pa) {
+			sa = pa[i]
+		}
+		if i < len(pb) {
+			sb = pb[i]
+		}
+
+		na, errA := strconv.Atoi(cmp.Or(sa, "0"))
+		nb, errB := strconv.Atoi(cmp.Or(sb, "0"))
+		c := cmp.Compare(na, nb)
+		if errA != nil || errB != nil {
+			c = strings.Compare(sa, sb)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
@@ -0,0 +1,310 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// aliasFlags adds a port flag whose deprecated name is old-port.
+func aliasFlags(t *testing.T, port *int) func(fs *pflag.FlagSet) {
+	return func(fs *pflag.FlagSet) {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.IntVar(port, "port", 80, "Port")
+		if err := AddAlias(flags, "port", Alias{Name: "old_port", Since: "v1.2.0", RemovedIn: "v2.0.0"}); err != nil {
+			t.Fatalf("AddAlias() error = %v", err)
+		}
+		fs.AddFlagSet(flags)
+	}
+}
+
+func TestConfigLoader_LoadConfig_Aliases(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		file     string
+		version  string
+		wantPort int
+		wantDeps []Deprecation
+		wantErr  error
+	}{
+		{
+			name:     "not used",
+			wantPort: 80,
+		},
+		{
+			name:     "flag",
+			args:     []string{"--old-port", "1000"},
+			wantPort: 1000,
+			wantDeps: []Deprecation{{Key: "port", Source: SourceFlag, Origin: "--old-port"}},
+		},
+		{
+			name:     "flag with value",
+			args:     []string{"--old_port=1000"},
+			wantPort: 1000,
+			wantDeps: []Deprecation{{Key: "port", Source: SourceFlag, Origin: "--old-port"}},
+		},
+		{
+			name:     "env",
+			env:      map[string]string{"MYAPP_OLD_PORT": "2000"},
+			wantPort: 2000,
+			wantDeps: []Deprecation{{Key: "port", Source: SourceEnv, Origin: "MYAPP_OLD_PORT"}},
+		},
+		{
+			name:     "new env takes precedence",
+			env:      map[string]string{"MYAPP_OLD_PORT": "2000", "MYAPP_PORT": "2001"},
+			wantPort: 2001,
+			wantDeps: []Deprecation{{Key: "port", Source: SourceEnv, Origin: "MYAPP_OLD_PORT"}},
+		},
+		{
+			name:     "file",
+			file:     "old-port = 3000\n",
+			wantPort: 3000,
+			wantDeps: []Deprecation{{Key: "port", Source: SourceFile, Origin: "config.local.toml"}},
+		},
+		{
+			name:     "new file key takes precedence",
+			file:     "old-port = 3000\nport = 3001\n",
+			wantPort: 3001,
+			wantDeps: []Deprecation{{Key: "port", Source: SourceFile, Origin: "config.local.toml"}},
+		},
+		{
+			name:     "before removal",
+			args:     []string{"--old-port", "1000"},
+			version:  "1.9.9",
+			wantPort: 1000,
+			wantDeps: []Deprecation{{Key: "port", Source: SourceFlag, Origin: "--old-port"}},
+		},
+		{
+			name:    "removed",
+			args:    []string{"--old-port", "1000"},
+			version: "v2.0.0",
+			wantErr: ErrRemovedKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.file != "" {
+				writeConfigFile(t, dir, "config.local.toml", tt.file)
+			}
+
+			unsetenv(t, "MYAPP_PORT", "MYAPP_OLD_PORT")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			var handled []Deprecation
+			loader := NewConfigLoader(
+				WithEnvPrefix("MYAPP"),
+				WithConfigPaths(dir),
+				WithVersion(tt.version),
+				WithDeprecationHandler(func(d Deprecation) {
+					handled = append(handled, d)
+				}),
+			)
+
+			var port int
+			_, err := loader.LoadConfig(
+				[]LoadOption{WithArgs(tt.args), WithConfigFile(tt.file != "")},
+				aliasFlags(t, &port),
+			)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LoadConfig() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if port != tt.wantPort {
+				t.Errorf("port = %v, want %v", port, tt.wantPort)
+			}
+
+			got := loader.Deprecations()
+			if len(got) != len(tt.wantDeps) || len(handled) != len(tt.wantDeps) {
+				t.Fatalf("Deprecations() = %v, handled %v, want %v", got, handled, tt.wantDeps)
+			}
+			for i, want := range tt.wantDeps {
+				want.Alias = Alias{Name: "old-port", Since: "v1.2.0", RemovedIn: "v2.0.0"}
+				if want.Source == SourceFile {
+					want.Origin = filepath.Join(dir, want.Origin)
+				}
+				if got[i] != want {
+					t.Errorf("Deprecations()[%d] = %+v, want %+v", i, got[i], want)
+				}
+				if handled[i] != want {
+					t.Errorf("handled[%d] = %+v, want %+v", i, handled[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigLoader_Reload_Aliases(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "config.local.toml", "old-port = 3000\n")
+	unsetenv(t, "PORT", "OLD_PORT")
+
+	var handled []Deprecation
+	loader := NewConfigLoader(WithConfigPaths(dir), WithDeprecationHandler(func(d Deprecation) {
+		handled = append(handled, d)
+	}))
+
+	var port int
+	if _, err := loader.LoadConfig([]LoadOption{WithArgs(nil), WithConfigFile(true)}, aliasFlags(t, &port)); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	writeConfigFile(t, dir, "config.local.toml", "old-port = 4000\n")
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if port != 4000 {
+		t.Errorf("port = %v, want 4000", port)
+	}
+	if len(handled) != 1 {
+		t.Errorf("handled = %v, want the deprecation reported once", handled)
+	}
+
+	t.Setenv("OLD_PORT", "5000")
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if port != 5000 {
+		t.Errorf("port = %v, want 5000", port)
+	}
+	if len(handled) != 2 || handled[1].Source != SourceEnv {
+		t.Errorf("handled = %v, want the env deprecation reported on reload", handled)
+	}
+}
+
+func TestAddAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		alias   string
+		wantErr bool
+	}{
+		{name: "valid", key: "port", alias: "old-port"},
+		{name: "unknown flag", key: "missing", alias: "old-port", wantErr: true},
+		{name: "existing flag", key: "port", alias: "host", wantErr: true},
+		{name: "empty", key: "port", alias: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			fs.Int("port", 80, "Port")
+			fs.String("host", "", "Host")
+
+			err := AddAlias(fs, tt.key, Alias{Name: tt.alias})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AddAlias() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeprecation_String(t *testing.T) {
+	tests := []struct {
+		name string
+		d    Deprecation
+		want string
+	}{
+		{
+			name: "versions",
+			d: Deprecation{
+				Key:    "port",
+				Alias:  Alias{Name: "old-port", Since: "v1.2.0", RemovedIn: "v2.0.0"},
+				Source: SourceEnv,
+				Origin: "MYAPP_OLD_PORT",
+			},
+			want: "'old-port' is deprecated since v1.2.0, use 'port' instead, it will be removed in v2.0.0 (env MYAPP_OLD_PORT)",
+		},
+		{
+			name: "no versions",
+			d:    Deprecation{Key: "port", Alias: Alias{Name: "old-port"}, Source: SourceFlag, Origin: "--old-port"},
+			want: "'old-port' is deprecated, use 'port' instead (flag --old-port)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeprecation_Fields(t *testing.T) {
+	tests := []struct {
+		name string
+		d    Deprecation
+		want map[string]any
+	}{
+		{
+			name: "versions",
+			d: Deprecation{
+				Key:    "port",
+				Alias:  Alias{Name: "old-port", Since: "v1.2.0", RemovedIn: "v2.0.0"},
+				Source: SourceEnv,
+				Origin: "MYAPP_OLD_PORT",
+			},
+			want: map[string]any{
+				"key":              "old-port",
+				"replacement":      "port",
+				"source":           "env",
+				"origin":           "MYAPP_OLD_PORT",
+				"deprecated_since": "v1.2.0",
+				"removed_in":       "v2.0.0",
+			},
+		},
+		{
+			name: "no versions",
+			d:    Deprecation{Key: "port", Alias: Alias{Name: "old-port"}, Source: SourceFlag, Origin: "--old-port"},
+			want: map[string]any{
+				"key":         "old-port",
+				"replacement": "port",
+				"source":      "flag",
+				"origin":      "--old-port",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.Fields(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"2.0.0-rc.1", "2.0.0", 0},
+		{"1.0.0+build", "1.0.1", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := compareVersions(tt.a, tt.b); got != tt.want {
+				t.Errorf("compareVersions(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package config
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// DiffStatus describes how a key differs between two environments.
+type DiffStatus string
+
+const (
+	// DiffChanged is a key set by the config files of both environments.
+	DiffChanged DiffStatus = "changed"
+
+	// DiffAdded is a key only set by the config files of the second environment.
+	DiffAdded DiffStatus = "added"
+
+	// DiffRemoved is a key only set by the config files of the first environment.
+	DiffRemoved DiffStatus = "removed"
+)
+
+// Difference is a key whose value differs between two environments.
+type Difference struct {
+	Key    string     `json:"key"`
+	Status DiffStatus `json:"status"`
+
+	// From and To are the value of the key in each environment, from a
+	// config file or the default value of the flag.
+	From Entry `json:"from"`
+	To   Entry `json:"to"`
+}
+
+// Diff resolves the configuration of the environments from and to with the
+// config files of each, overlays included, and the defaults of the flags
+// added by flagSets, and returns the keys whose value differs, sorted by key.
+// Flags, providers and environment variables, except to interpolate values,
+// are left out so only the config files are compared, and keys of the files
+// that aren't flags are compared too. Values of keys that look sensitive are
+// redacted and references, such as "file:///run/secrets/key", aren't resolved.
+//
+// The defaults are those recorded by LoadConfig when a configuration was
+// loaded, and the flags are then those LoadConfig parsed rather than those
+// of flagSets. Registered components and the variables bound to the flags
+// are left untouched, see ConfigLoader.FlagSet.
+func (cl *ConfigLoader) Diff(from, to string, options []LoadOption, flagSets ...func(fs *pflag.FlagSet)) ([]Difference, error) {
+	opts := &loadOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+
+	fs := cl.defaultFlagSet(opts, flagSets)
+	defaults := snapshotFlags(fs)
+
+	a, err := cl.resolveEnvironment(fs, from, opts, defaults)
+	if err != nil {
+		return nil, err
+	}
+	b, err := cl.resolveEnvironment(fs, to, opts, defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := slices.Sorted(maps.Keys(a))
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	var diffs []Difference
+	for _, key := range keys {
+		ea, eb := a[key], b[key]
+		ea.Key, eb.Key = key, key
+		ea.Source, eb.Source = cmp.Or(ea.Source, SourceDefault), cmp.Or(eb.Source, SourceDefault)
+		if reflect.DeepEqual(ea.Value, eb.Value) {
+			continue
+		}
+
+		d := Difference{Key: key, Status: DiffChanged, From: ea, To: eb}
+		switch {
+		case ea.Source == SourceDefault && eb.Source != SourceDefault:
+			d.Status = DiffAdded
+		case ea.Source != SourceDefault && eb.Source == SourceDefault:
+			d.Status = DiffRemoved
+		}
+
+		if isSensitiveKey(key) {
+			d.From.Value, d.To.Value = redactedOrNil(d.From.Value), redactedOrNil(d.To.Value)
+		}
+
+		diffs = append(diffs, d)
+	}
+
+	return diffs, nil
+}
+
+// resolveEnvironment returns the value of every key of fs, and of the config
+// files of env, in the environment env.
+func (cl *ConfigLoader) resolveEnvironment(fs *pflag.FlagSet, env string, opts *loadOptions, defaults map[string]any) (map[string]Entry, error) {
+	defer func() { _ = setFlags(fs, defaults) }()
+
+	layers := viper.New()
+	layers.Set(EnvName, env)
+	files, err := cl.readConfigFiles(configLayers(layers, opts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config files of '%s': %w", env, err)
+	}
+
+	aliases := flagAliases(fs)
+	for i := range files {
+		files[i].aliases = renameAliases(files[i].values, aliases)
+	}
+	if err := cl.interpolate(fs, files); err != nil {
+		return nil, fmt.Errorf("failed to read config files of '%s': %w", env, err)
+	}
+
+	v := viper.New()
+	for _, file := range files {
+		if err := v.MergeConfigMap(file.values); err != nil {
+			return nil, fmt.Errorf("failed to merge values of '%s': %w", file.path, err)
+		}
+	}
+
+	entries := make(map[string]Entry)
+	var errs []error
+	fs.VisitAll(func(f *pflag.Flag) {
+		if _, ok := f.Annotations[internalAnnotation]; ok || f.Name == EnvName {
+			return
+		}
+
+		entry := Entry{Key: f.Name, Value: typedFlagValue(f), Source: SourceDefault}
+		if v.IsSet(f.Name) {
+			entry.Source, entry.Origin = fileSource(f.Name, files)
+
+			value := v.Get(f.Name)
+			if cl.isReference(value) {
+				entry.Value = formatFlagValue(value)
+			} else if err := setFlagValue(f, value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid value for '%s' in '%s': %w", f.Name, entry.Origin, err))
+			} else {
+				entry.Value = typedFlagValue(f)
+			}
+		}
+		entries[f.Name] = entry
+	})
+
+	for _, file := range files {
+		for key, value := range file.values {
+			if fs.Lookup(key) == nil && key != schemaKey {
+				entries[key] = Entry{Key: key, Value: value, Source: file.source, Origin: file.path}
+			}
+		}
+	}
+
+	return entries, errors.Join(errs...)
+}
+
+// fileSource returns where the value of key comes from in files.
+func fileSource(key string, files []configFile) (Source, string) {
+	for i := len(files) - 1; i >= 0; i-- {
+		if _, ok := files[i].values[key]; ok {
+			return files[i].source, files[i].path
+		}
+	}
+	return SourceDefault, ""
+}
+
+// isReference reports whether the value, or an element of it, is a
+// reference resolved by a Resolver.
+func (cl *ConfigLoader) isReference(value any) bool {
+	switch val := value.(type) {
+	case string:
+		for prefix := range cl.resolvers {
+			if strings.HasPrefix(val, prefix) {
+				return true
+			}
+		}
+	case []any:
+		return slices.ContainsFunc(val, cl.isReference)
+	}
+	return false
+}
+
+// redactedOrNil returns Redacted, or nil if value isn't set.
+func redactedOrNil(value any) any {
+	if value == nil || value == "" {
+		return value
+	}
+	return Redacted
+}
+
+// DiffCommand returns a "diff" command printing the differences between the
+// configuration of the two environments given as arguments, e.g.
+// "myapp config diff dev prod", with the flags added by flagSets.
+func (cl *ConfigLoader) DiffCommand(options []LoadOption, flagSets ...func(fs *pflag.FlagSet)) *Command {
+	var format string
+	return &Command{
+		Name:  "diff",
+		Usage: "Compare the configuration files of two environments",
+		Flags: func(fs *pflag.FlagSet) {
+			fs.StringVar(&format, "format", FormatText,
+				fmt.Sprintf("Output format\nValues: %s, %s", FormatText, FormatJSON))
+			_ = fs.SetAnnotation("format", internalAnnotation, nil)
+		},
+		Run: func(_ *Config, args []string) error {
+			if len(args) != 2 {
+				return fmt.Errorf("expected two environments, e.g. 'diff dev prod', got %d arguments", len(args))
+			}
+
+			diffs, err := cl.Diff(args[0], args[1], options, flagSets...)
+			if err != nil {
+				return err
+			}
+			return PrintDiff(cl.stdout, args[0], args[1], diffs, format)
+		},
+	}
+}
+
+// PrintDiff writes the differences between the environments from and to
+// returned by ConfigLoader.Diff to w, as text or JSON. In text, keys only
+// set in from are marked with "-", keys only set in to with "+" and the
+// others with "~".
+func PrintDiff(w io.Writer, from, to string, diffs []Difference, format string) error {
+	switch strings.ToLower(format) {
+	case FormatText:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(tw, "  KEY\t%s\t%s\n", from, to)
+		for _, d := range diffs {
+			_, _ = fmt.Fprintf(tw, "%s %s\t%s\t%s\n", diffMarker(d.Status), d.Key, formatDiffValue(d.From), formatDiffValue(d.To))
+		}
+		return tw.Flush()
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if diffs == nil {
+			diffs = []Difference{}
+		}
+		return enc.Encode(diffs)
+	default:
+		return fmt.Errorf("invalid format '%s', must be one of: %s, %s", format, FormatText, FormatJSON)
+	}
+}
+
+// diffMarker returns the marker of a difference in text.
+func diffMarker(status DiffStatus) string {
+	switch status {
+	case DiffAdded:
+		return "+"
+	case DiffRemoved:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// formatDiffValue formats the value of a key in an environment, e.g.
+// "8080 (default)".
+func formatDiffValue(e Entry) string {
+	if e.Value == nil {
+		return "(unset)"
+	}
+
+	s := formatFlagValue(e.Value)
+	if s == "" {
+		s = "(empty)"
+	}
+	if e.Source == SourceDefault {
+		s += " (default)"
+	}
+	return s
+}
@@ -0,0 +1,272 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// diffTest writes the config files of the dev and prod environments.
+func diffTest(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "config.toml", "timeout = \"30s\"\nworkers = 4\n")
+	writeConfigFile(t, dir, "config.dev.toml", `port = 8080
+timeout = "30s"
+session-secret = "dev"
+db-password = "file:///run/secrets/db"
+debug = true
+legacy = "dev"
+`)
+	writeConfigFile(t, dir, "config.prod.toml", `port = 9090
+timeout = "1m"
+session-secret = "prod"
+db-password = "file:///run/secrets/db"
+origins = ["https://example.com"]
+`)
+	writeConfigFile(t, dir, "config.prod.local.toml", "workers = 16\n")
+	return dir
+}
+
+func diffTestFlags(port *int) func(fs *pflag.FlagSet) {
+	return func(fs *pflag.FlagSet) {
+		fs.IntVar(port, "port", 80, "Port")
+		fs.Duration("timeout", 10*time.Second, "Timeout")
+		fs.Int("workers", 1, "Workers")
+		fs.String("session-secret", "", "Session secret")
+		fs.String("db-password", "", "Database password")
+		fs.Bool("debug", false, "Debug")
+		fs.StringSlice("origins", nil, "Origins")
+	}
+}
+
+func TestConfigLoader_Diff(t *testing.T) {
+	dir := diffTest(t)
+	unsetenv(t, "PORT")
+	t.Setenv("PORT", "1")
+
+	var port int
+	loader := NewConfigLoader(WithConfigPaths(dir))
+	diffs, err := loader.Diff("dev", "prod", nil, diffTestFlags(&port))
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	dev := filepath.Join(dir, "config.dev.toml")
+	prod := filepath.Join(dir, "config.prod.toml")
+	want := []Difference{
+		{
+			Key:    "debug",
+			Status: DiffRemoved,
+			From:   Entry{Key: "debug", Value: true, Source: SourceFile, Origin: dev},
+			To:     Entry{Key: "debug", Value: false, Source: SourceDefault},
+		},
+		{
+			Key:    "legacy",
+			Status: DiffRemoved,
+			From:   Entry{Key: "legacy", Value: "dev", Source: SourceFile, Origin: dev},
+			To:     Entry{Key: "legacy", Source: SourceDefault},
+		},
+		{
+			Key:    "origins",
+			Status: DiffAdded,
+			From:   Entry{Key: "origins", Value: []string(nil), Source: SourceDefault},
+			To:     Entry{Key: "origins", Value: []string{"https://example.com"}, Source: SourceFile, Origin: prod},
+		},
+		{
+			Key:    "port",
+			Status: DiffChanged,
+			From:   Entry{Key: "port", Value: int64(8080), Source: SourceFile, Origin: dev},
+			To:     Entry{Key: "port", Value: int64(9090), Source: SourceFile, Origin: prod},
+		},
+		{
+			Key:    "session-secret",
+			Status: DiffChanged,
+			From:   Entry{Key: "session-secret", Value: Redacted, Source: SourceFile, Origin: dev},
+			To:     Entry{Key: "session-secret", Value: Redacted, Source: SourceFile, Origin: prod},
+		},
+		{
+			Key:    "timeout",
+			Status: DiffChanged,
+			From:   Entry{Key: "timeout", Value: "30s", Source: SourceFile, Origin: dev},
+			To:     Entry{Key: "timeout", Value: "1m0s", Source: SourceFile, Origin: prod},
+		},
+		{
+			Key:    "workers",
+			Status: DiffChanged,
+			From:   Entry{Key: "workers", Value: int64(4), Source: SourceFile, Origin: filepath.Join(dir, "config.toml")},
+			To:     Entry{Key: "workers", Value: int64(16), Source: SourceFile, Origin: filepath.Join(dir, "config.prod.local.toml")},
+		},
+	}
+
+	if len(diffs) != len(want) {
+		t.Fatalf("Diff() = %+v, want %+v", diffs, want)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(diffs[i], want[i]) {
+			t.Errorf("Diff()[%d] = %+v, want %+v", i, diffs[i], want[i])
+		}
+	}
+
+	if port != 80 {
+		t.Errorf("port = %v, want the default restored", port)
+	}
+}
+
+func TestConfigLoader_Diff_AfterLoad(t *testing.T) {
+	dir := diffTest(t)
+	writeConfigFile(t, dir, "config.dev.local.toml", "admin-port = 9000\n")
+	t.Setenv("PORT", "7070")
+	t.Setenv("ORIGINS", "https://example.org")
+	t.Setenv("ADMIN_PORT", "7000")
+
+	admin := &testComponent{name: "Admin", key: "admin-port", Port: 81}
+	loader := NewConfigLoader(WithConfigPaths(dir)).Register(admin)
+
+	var port int
+	if _, err := loader.LoadConfig([]LoadOption{WithArgs(nil)}, diffTestFlags(&port)); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if admin.Port != 7000 {
+		t.Fatalf("admin-port = %v, want 7000 from the environment", admin.Port)
+	}
+
+	diffs, err := loader.Diff("dev", "prod", nil, diffTestFlags(&port))
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	want := Difference{
+		Key:    "admin-port",
+		Status: DiffRemoved,
+		From:   Entry{Key: "admin-port", Value: int64(9000), Source: SourceFile, Origin: filepath.Join(dir, "config.dev.local.toml")},
+		To:     Entry{Key: "admin-port", Value: int64(81), Source: SourceDefault},
+	}
+	if len(diffs) == 0 || !reflect.DeepEqual(diffs[0], want) {
+		t.Errorf("Diff()[0] = %+v, want %+v", diffs, want)
+	}
+
+	for _, d := range diffs {
+		if v, ok := d.From.Value.([]string); d.Key == "origins" && (!ok || len(v) != 0) {
+			t.Errorf("Diff() origins = %+v, want the default in dev", d)
+		}
+	}
+
+	if admin.Port != 7000 {
+		t.Errorf("admin-port = %v, want the loaded value left untouched", admin.Port)
+	}
+	if port != 7070 {
+		t.Errorf("port = %v, want the loaded value left untouched", port)
+	}
+
+	fs := loader.FlagSet(nil, diffTestFlags(&port))
+	for name, want := range map[string]string{"admin-port": "81", "port": "80", "origins": "[]"} {
+		if def := fs.Lookup(name).DefValue; def != want {
+			t.Errorf("FlagSet() default of %s = %v, want %v", name, def, want)
+		}
+	}
+	if port != 7070 {
+		t.Errorf("port = %v, want FlagSet to leave the loaded value untouched", port)
+	}
+}
+
+func TestConfigLoader_Diff_MissingEnvironment(t *testing.T) {
+	loader := NewConfigLoader(WithConfigPaths(diffTest(t)))
+	if _, err := loader.Diff("dev", "staging", nil); err == nil {
+		t.Error("Diff() expected error for an environment without config file")
+	}
+}
+
+func TestPrintDiff(t *testing.T) {
+	diffs := []Difference{
+		{
+			Key:    "debug",
+			Status: DiffRemoved,
+			From:   Entry{Key: "debug", Value: true, Source: SourceFile},
+			To:     Entry{Key: "debug", Value: false, Source: SourceDefault},
+		},
+		{
+			Key:    "legacy",
+			Status: DiffRemoved,
+			From:   Entry{Key: "legacy", Value: "dev", Source: SourceFile},
+			To:     Entry{Key: "legacy", Source: SourceDefault},
+		},
+		{
+			Key:    "origins",
+			Status: DiffAdded,
+			From:   Entry{Key: "origins", Value: []string{}, Source: SourceDefault},
+			To:     Entry{Key: "origins", Value: []string{"a", "b"}, Source: SourceFile},
+		},
+		{
+			Key:    "port",
+			Status: DiffChanged,
+			From:   Entry{Key: "port", Value: int64(8080), Source: SourceFile},
+			To:     Entry{Key: "port", Value: int64(9090), Source: SourceFile},
+		},
+	}
+
+	var text bytes.Buffer
+	if err := PrintDiff(&text, "dev", "prod", diffs, FormatText); err != nil {
+		t.Fatalf("PrintDiff() error = %v", err)
+	}
+
+	want := "" +
+		"  KEY      dev                prod\n" +
+		"- debug    true               false (default)\n" +
+		"- legacy   dev                (unset)\n" +
+		"+ origins  (empty) (default)  a,b\n" +
+		"~ port     8080               9090\n"
+	if got := text.String(); got != want {
+		t.Errorf("PrintDiff() =\n%s\nwant\n%s", got, want)
+	}
+
+	var out bytes.Buffer
+	if err := PrintDiff(&out, "dev", "prod", diffs, FormatJSON); err != nil {
+		t.Fatalf("PrintDiff() error = %v", err)
+	}
+	var decoded []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("PrintDiff() returned invalid JSON: %v", err)
+	}
+	if len(decoded) != len(diffs) || decoded[2]["status"] != string(DiffAdded) {
+		t.Errorf("PrintDiff() = %s", out.String())
+	}
+
+	if err := PrintDiff(&out, "dev", "prod", diffs, "yaml"); err == nil || !strings.Contains(err.Error(), "invalid format") {
+		t.Errorf("PrintDiff() error = %v, want invalid format", err)
+	}
+}
+
+func TestConfigLoader_DiffCommand(t *testing.T) {
+	dir := diffTest(t)
+
+	var out bytes.Buffer
+	loader := NewConfigLoader(WithConfigPaths(dir))
+	loader.stdout = &out
+
+	var port int
+	root := &Command{
+		Commands: []*Command{
+			{Name: "config", Commands: []*Command{loader.DiffCommand(nil, diffTestFlags(&port))}},
+		},
+	}
+
+	if err := loader.Execute(root, WithArgs([]string{"config", "diff", "dev", "prod"})); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "~ port") || !strings.Contains(out.String(), "+ origins") {
+		t.Errorf("output = %s, want the differences", out.String())
+	}
+
+	err := loader.Execute(root, WithArgs([]string{"config", "diff", "dev"}))
+	if err == nil || !strings.Contains(err.Error(), "expected two environments") {
+		t.Errorf("Execute() error = %v, want expected two environments", err)
+	}
+}
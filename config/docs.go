@@ -0,0 +1,246 @@
+package config
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// JSONSchemaDraft is the JSON Schema version of the schemas generated by
+// JSONSchema.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches the durations accepted by time.ParseDuration.
+const durationPattern = `^[-+]?(0|([0-9]*(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$`
+
+// JSONSchema returns a JSON Schema describing config files holding the flags
+// of fs, with their type, default value and description. It allows editors
+// to complete and check config files, and CI to validate them. Flags
+// controlling the loader, such as --print-config, are left out, and
+// deprecated keys are marked as such.
+// See ConfigLoader.FlagSet to get the flag set LoadConfig parses.
+func JSONSchema(fs *pflag.FlagSet) ([]byte, error) {
+	properties := map[string]any{
+		// lets JSON config files reference the schema
+		"$schema": map[string]any{"type": "string"},
+	}
+
+	fs.VisitAll(func(f *pflag.Flag) {
+		if _, ok := f.Annotations[internalAnnotation]; ok {
+			return
+		}
+
+		property := flagSchema(f.Value.Type())
+		if f.Usage != "" {
+			property["description"] = f.Usage
+		}
+		if def := defaultValue(f); def != nil {
+			property["default"] = def
+		}
+		properties[f.Name] = property
+
+		// deprecated keys are still accepted in config files
+		for _, alias := range aliasesOf(f) {
+			deprecated := flagSchema(f.Value.Type())
+			deprecated["description"] = fmt.Sprintf("Deprecated, use '%s' instead.", f.Name)
+			deprecated["deprecated"] = true
+			properties[alias.Name] = deprecated
+		}
+	})
+
+	schema := map[string]any{
+		"$schema":              JSONSchemaDraft,
+		"title":                fs.Name(),
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// flagSchema returns the JSON Schema of the values of a flag type.
+func flagSchema(typ string) map[string]any {
+	if elem, ok := strings.CutSuffix(typ, "Slice"); ok {
+		return listSchema(flagSchema(elem))
+	}
+	if typ == "stringArray" {
+		return listSchema(flagSchema("string"))
+	}
+	if elem, ok := strings.CutPrefix(typ, "stringTo"); ok {
+		return map[string]any{"type": "object", "additionalProperties": flagSchema(strings.ToLower(elem))}
+	}
+
+	switch {
+	case typ == "bool":
+		return map[string]any{"type": "boolean"}
+	case typ == "count" || strings.HasPrefix(typ, "int"):
+		return map[string]any{"type": "integer"}
+	case strings.HasPrefix(typ, "uint"):
+		return map[string]any{"type": "integer", "minimum": 0}
+	case strings.HasPrefix(typ, "float"):
+		return map[string]any{"type": "number"}
+	case typ == "duration":
+		return map[string]any{"type": "string", "pattern": durationPattern}
+	case typ == "byteSize":
+		return map[string]any{"type": []string{"string", "integer"}}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
+
+// listSchema returns the JSON Schema of a list of items, given as an array or,
+// like in environment variables, as a comma-separated string.
+func listSchema(items map[string]any) map[string]any {
+	return map[string]any{
+		"oneOf": []any{
+			map[string]any{"type": "array", "items": items},
+			map[string]any{"type": "string"},
+		},
+	}
+}
+
+// defaultValue returns the default value of the flag as a bool, number, list,
+// map or string, or nil if it has none.
+func defaultValue(f *pflag.Flag) any {
+	typ := f.Value.Type()
+	s := f.DefValue
+
+	if _, ok := f.Value.(pflag.SliceValue); ok || strings.HasPrefix(typ, "stringTo") {
+		s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+		if s == "" {
+			return nil
+		}
+
+		values, err := csv.NewReader(strings.NewReader(s)).Read()
+		if err != nil {
+			values = strings.Split(s, ",")
+		}
+
+		if elem, ok := strings.CutPrefix(typ, "stringTo"); ok {
+			m := make(map[string]any, len(values))
+			for _, value := range values {
+				k, v, _ := strings.Cut(value, "=")
+				m[k] = typedValue(strings.ToLower(elem), v)
+			}
+			return m
+		}
+
+		elem := strings.TrimSuffix(typ, "Slice")
+		list := make([]any, len(values))
+		for i, value := range values {
+			list[i] = typedValue(elem, value)
+		}
+		return list
+	}
+
+	if s == "" {
+		return nil
+	}
+	return typedValue(typ, s)
+}
+
+// typedValue converts s to the JSON type of the flag type, falling back to s.
+func typedValue(typ, s string) any {
+	switch {
+	case typ == "bool":
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case typ == "count" || strings.HasPrefix(typ, "int"):
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+	case strings.HasPrefix(typ, "uint"):
+		if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return u
+		}
+	case strings.HasPrefix(typ, "float"):
+		if fl, err := strconv.ParseFloat(s, 64); err == nil {
+			return fl
+		}
+	}
+	return s
+}
+
+// Markdown writes a Markdown table documenting every flag of fs with its
+// environment variable, config file key, default value and description.
+// See ConfigLoader.FlagSet to get the flag set LoadConfig parses.
+func (cl *ConfigLoader) Markdown(w io.Writer, fs *pflag.FlagSet) error {
+	rows := [][]string{{"Flag", "Environment variable", "File key", "Default", "Description"}}
+	fs.VisitAll(func(f *pflag.Flag) {
+		_, internal := f.Annotations[internalAnnotation]
+
+		env, key := "", ""
+		if !internal {
+			env = markdownCode(strings.Join(cl.envNames(f), ", "))
+			key = markdownCode(f.Name)
+		}
+
+		rows = append(rows, []string{
+			markdownCode("--" + f.Name),
+			env,
+			key,
+			markdownCode(markdownDefault(f)),
+			markdownText(f.Usage),
+		})
+	})
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+
+	var b strings.Builder
+	for i, row := range rows {
+		writeMarkdownRow(&b, row, widths)
+		if i == 0 {
+			separator := make([]string, len(widths))
+			for j, width := range widths {
+				separator[j] = strings.Repeat("-", width)
+			}
+			writeMarkdownRow(&b, separator, widths)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdownRow writes a table row, padding the cells to widths.
+func writeMarkdownRow(b *strings.Builder, row []string, widths []int) {
+	b.WriteString("|")
+	for i, cell := range row {
+		_, _ = fmt.Fprintf(b, " %s%s |", cell, strings.Repeat(" ", widths[i]-len([]rune(cell))))
+	}
+	b.WriteString("\n")
+}
+
+// markdownDefault returns the default value of the flag as shown in the docs.
+func markdownDefault(f *pflag.Flag) string {
+	if f.DefValue == "[]" {
+		return ""
+	}
+	return f.DefValue
+}
+
+// markdownCode formats s as inline code, or returns an empty string.
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(s, "|", `\|`) + "`"
+}
+
+// markdownText escapes s for a table cell.
+func markdownText(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "<br>")
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func docsFlagSet(loader *ConfigLoader) *pflag.FlagSet {
+	return loader.FlagSet([]LoadOption{WithPrintConfig(true)}, func(fs *pflag.FlagSet) {
+		fs.Int("port", 8080, "Server port")
+		fs.Bool("debug", false, "Enable debug mode")
+		fs.Duration("timeout", 30*time.Second, "Request timeout")
+		fs.Uint("workers", 4, "Number of workers")
+		fs.Float64("ratio", 0.5, "Sampling ratio")
+		fs.StringSlice("origins", []string{"a", "b"}, "Allowed origins")
+		fs.IntSlice("codes", nil, "Status codes")
+		fs.StringToString("labels", map[string]string{"team": "core"}, "Labels")
+		_ = AddAlias(fs, "port", Alias{Name: "listen-port"})
+	})
+}
+
+func TestConfigLoader_FlagSet(t *testing.T) {
+	fs := docsFlagSet(NewConfigLoader())
+
+	for _, name := range []string{AppName, EnvName, PrintConfig, "port", "origins"} {
+		if fs.Lookup(name) == nil {
+			t.Errorf("FlagSet() is missing flag %s", name)
+		}
+	}
+
+	if got := fs.Lookup(AppName).DefValue; got != DefaultAppName {
+		t.Errorf("%s default = %v, want %v", AppName, got, DefaultAppName)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	b, err := JSONSchema(docsFlagSet(NewConfigLoader()))
+	if err != nil {
+		t.Fatalf("JSONSchema() error = %v", err)
+	}
+
+	var schema struct {
+		Schema               string                    `json:"$schema"`
+		Type                 string                    `json:"type"`
+		AdditionalProperties bool                      `json:"additionalProperties"`
+		Properties           map[string]map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal(b, &schema); err != nil {
+		t.Fatalf("JSONSchema() returned invalid JSON: %v", err)
+	}
+
+	if schema.Schema != JSONSchemaDraft || schema.Type != "object" || schema.AdditionalProperties {
+		t.Errorf("JSONSchema() = %s, want an object schema without additional properties", b)
+	}
+
+	if _, ok := schema.Properties[PrintConfig]; ok {
+		t.Errorf("JSONSchema() has property %s, want internal flags left out", PrintConfig)
+	}
+
+	tests := []struct {
+		key  string
+		want map[string]any
+	}{
+		{
+			key:  "port",
+			want: map[string]any{"type": "integer", "default": float64(8080), "description": "Server port"},
+		},
+		{
+			key:  "debug",
+			want: map[string]any{"type": "boolean", "default": false, "description": "Enable debug mode"},
+		},
+		{
+			key: "timeout",
+			want: map[string]any{
+				"type": "string", "pattern": durationPattern, "default": "30s", "description": "Request timeout",
+			},
+		},
+		{
+			key:  "workers",
+			want: map[string]any{"type": "integer", "minimum": float64(0), "default": float64(4), "description": "Number of workers"},
+		},
+		{
+			key:  "ratio",
+			want: map[string]any{"type": "number", "default": 0.5, "description": "Sampling ratio"},
+		},
+		{
+			key: "origins",
+			want: map[string]any{
+				"oneOf": []any{
+					map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					map[string]any{"type": "string"},
+				},
+				"default": []any{"a", "b"}, "description": "Allowed origins",
+			},
+		},
+		{
+			key: "codes",
+			want: map[string]any{
+				"oneOf": []any{
+					map[string]any{"type": "array", "items": map[string]any{"type": "integer"}},
+					map[string]any{"type": "string"},
+				},
+				"description": "Status codes",
+			},
+		},
+		{
+			key: "labels",
+			want: map[string]any{
+				"type": "object", "additionalProperties": map[string]any{"type": "string"},
+				"default": map[string]any{"team": "core"}, "description": "Labels",
+			},
+		},
+		{
+			key: "listen-port",
+			want: map[string]any{
+				"type": "integer", "deprecated": true, "description": "Deprecated, use 'port' instead.",
+			},
+		},
+		{
+			key:  "$schema",
+			want: map[string]any{"type": "string"},
+		},
+		{
+			key:  AppName,
+			want: map[string]any{"type": "string", "default": DefaultAppName, "description": "The name of the application."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := schema.Properties[tt.key]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("property = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigLoader_Markdown(t *testing.T) {
+	loader := NewConfigLoader(WithEnvPrefix("MYAPP"))
+	fs := loader.FlagSet(nil, func(fs *pflag.FlagSet) {
+		fs.Int("port", 8080, "Server port")
+		fs.StringSlice("origins", nil, "Allowed origins")
+		fs.String("secret", "", "Secret\nwith | pipe")
+		_ = fs.SetAnnotation("secret", envAnnotation, []string{"SECRET"})
+	})
+
+	var buf bytes.Buffer
+	if err := loader.Markdown(&buf, fs); err != nil {
+		t.Fatalf("Markdown() error = %v", err)
+	}
+
+	want := "" +
+		"| Flag         | Environment variable | File key   | Default | Description                                                             |\n" +
+		"| ------------ | -------------------- | ---------- | ------- | ----------------------------------------------------------------------- |\n" +
+		"| `--app-name` | `MYAPP_APP_NAME`     | `app-name` | `app`   | The name of the application.                                            |\n" +
+		"| `--env-name` | `MYAPP_ENV_NAME`     | `env-name` | `local` | The environment of the application. Used to load the right config file. |\n" +
+		"| `--origins`  | `MYAPP_ORIGINS`      | `origins`  |         | Allowed origins                                                         |\n" +
+		"| `--port`     | `MYAPP_PORT`         | `port`     | `8080`  | Server port                                                             |\n" +
+		"| `--secret`   | `SECRET`             | `secret`   |         | Secret<br>with \\| pipe                                                  |\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("Markdown() =\n%s\nwant\n%s", got, want)
+	}
+}
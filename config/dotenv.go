@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/subosito/gotenv"
+)
+
+// DotenvFileName is the name of the file, in the working directory, holding
+// environment variables for local development. Variables for a specific
+// environment are read from DotenvFileName + "." + <env-name>.
+const DotenvFileName = ".env"
+
+// WithDotenv loads environment variables from the .env and .env.<env-name>
+// files of the working directory, if they exist. Their variables are kept by
+// the loader, at the precedence of environment variables, rather than set in
+// the environment of the process. Variables set in the process environment
+// are never overridden, even when empty, and variables of .env.<env-name>
+// take precedence over those of .env.
+func WithDotenv(enabled bool) LoadOption {
+	return func(o *loadOptions) {
+		o.dotenv = enabled
+	}
+}
+
+// dotenvVar is an environment variable set from a .env file.
+type dotenvVar struct {
+	value string
+	file  string
+}
+
+// loadDotenv reads the variables of the .env files and sets the flags of fs
+// they set in v. The environment name used to find .env.<env-name> may itself
+// be set by .env.
+func (cl *ConfigLoader) loadDotenv(v *viper.Viper, fs *pflag.FlagSet) error {
+	vars, err := readDotenv(DotenvFileName)
+	if err != nil {
+		return err
+	}
+	cl.setDotenv(vars)
+	cl.applyDotenv(v, fs)
+
+	envName := strings.ToLower(v.GetString(EnvName))
+	if envName == "" {
+		return nil
+	}
+
+	envVars, err := readDotenv(DotenvFileName + "." + envName)
+	if err != nil {
+		return err
+	}
+	maps.Copy(vars, envVars)
+	cl.setDotenv(vars)
+	cl.applyDotenv(v, fs)
+
+	return nil
+}
+
+// applyDotenv sets in v the value of every flag of fs that is set by a .env
+// file, unless it's given on the command line or set by the process
+// environment, which viper reads itself.
+func (cl *ConfigLoader) applyDotenv(v *viper.Viper, fs *pflag.FlagSet) {
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			return
+		}
+		if _, value, file, ok := cl.flagEnv(f); ok && file != "" {
+			v.Set(f.Name, value)
+		}
+	})
+}
+
+// setDotenv replaces the variables of the .env files.
+func (cl *ConfigLoader) setDotenv(vars map[string]dotenvVar) {
+	cl.dotenvMu.Lock()
+	defer cl.dotenvMu.Unlock()
+	cl.dotenv = vars
+}
+
+// dotenvVars returns the variables of the .env files.
+func (cl *ConfigLoader) dotenvVars() map[string]dotenvVar {
+	cl.dotenvMu.RLock()
+	defer cl.dotenvMu.RUnlock()
+	return cl.dotenv
+}
+
+// lookupEnv returns the value of the environment variable name from the
+// process environment or, when it isn't set there, from the .env files.
+func (cl *ConfigLoader) lookupEnv(name string) (string, bool) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+	dv, ok := cl.dotenvVars()[name]
+	return dv.value, ok
+}
+
+// flagEnv returns the non-empty environment variable setting the flag f, its
+// value and the .env file it comes from, if any. Variables of the process
+// environment take precedence over those of the .env files, even when they're
+// set to an empty value, which leaves the flag unset like viper does.
+func (cl *ConfigLoader) flagEnv(f *pflag.Flag) (string, string, string, bool) {
+	envs := append(cl.envNames(f), cl.aliasEnvs(f)...)
+	for _, env := range envs {
+		if value, ok := os.LookupEnv(env); ok && value != "" {
+			return env, value, "", true
+		}
+	}
+
+	vars := cl.dotenvVars()
+	for _, env := range envs {
+		if _, ok := os.LookupEnv(env); ok {
+			continue
+		}
+		if dv, ok := vars[env]; ok && dv.value != "" {
+			return env, dv.value, dv.file, true
+		}
+	}
+	return "", "", "", false
+}
+
+// readDotenv returns the variables of a .env file, or none if it doesn't exist.
+func readDotenv(path string) (map[string]dotenvVar, error) {
+	env, err := gotenv.Read(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return map[string]dotenvVar{}, nil
+		}
+		return nil, fmt.Errorf("failed to read env file '%s': %w", path, err)
+	}
+
+	vars := make(map[string]dotenvVar, len(env))
+	for key, value := range env {
+		vars[key] = dotenvVar{value: value, file: path}
+	}
+	return vars, nil
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// unsetenv unsets the environment variables for the duration of the test.
+func unsetenv(t *testing.T, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		t.Setenv(key, "")
+		_ = os.Unsetenv(key)
+	}
+}
+
+func TestConfigLoader_LoadConfig_Dotenv(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		env      map[string]string
+		loadOpts []LoadOption
+		wantPort int
+		wantName string
+		wantEnv  string
+	}{
+		{
+			name: "env file",
+			files: map[string]string{
+				".env": "MYAPP_PORT=1000\nMYAPP_NAME=dotenv\n",
+			},
+			loadOpts: []LoadOption{WithDotenv(true)},
+			wantPort: 1000,
+			wantName: "dotenv",
+			wantEnv:  DefaultEnvName,
+		},
+		{
+			name: "environment specific file takes precedence",
+			files: map[string]string{
+				".env":      "MYAPP_PORT=1000\nMYAPP_NAME=dotenv\nMYAPP_ENV_NAME=test\n",
+				".env.test": "MYAPP_PORT=2000\n",
+			},
+			loadOpts: []LoadOption{WithDotenv(true)},
+			wantPort: 2000,
+			wantName: "dotenv",
+			wantEnv:  "test",
+		},
+		{
+			name: "process environment is not overridden",
+			files: map[string]string{
+				".env": "MYAPP_PORT=1000\nMYAPP_NAME=dotenv\n",
+			},
+			env:      map[string]string{"MYAPP_NAME": "process"},
+			loadOpts: []LoadOption{WithDotenv(true)},
+			wantPort: 1000,
+			wantName: "process",
+			wantEnv:  DefaultEnvName,
+		},
+		{
+			name: "empty process environment is not overridden",
+			files: map[string]string{
+				".env": "MYAPP_PORT=1000\nMYAPP_NAME=dotenv\n",
+			},
+			env:      map[string]string{"MYAPP_NAME": ""},
+			loadOpts: []LoadOption{WithDotenv(true)},
+			wantPort: 1000,
+			wantName: "default",
+			wantEnv:  DefaultEnvName,
+		},
+		{
+			name: "env file overrides config file",
+			files: map[string]string{
+				".env":              "MYAPP_PORT=1000\n",
+				"config.local.toml": "port = 3000\nname = \"file\"\n",
+			},
+			loadOpts: []LoadOption{WithDotenv(true), WithConfigFile(true)},
+			wantPort: 1000,
+			wantName: "file",
+			wantEnv:  DefaultEnvName,
+		},
+		{
+			name: "disabled",
+			files: map[string]string{
+				".env": "MYAPP_PORT=1000\n",
+			},
+			wantPort: 80,
+			wantName: "default",
+			wantEnv:  DefaultEnvName,
+		},
+		{
+			name:     "missing files",
+			loadOpts: []LoadOption{WithDotenv(true)},
+			wantPort: 80,
+			wantName: "default",
+			wantEnv:  DefaultEnvName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeConfigFile(t, dir, name, content)
+			}
+			t.Chdir(dir)
+
+			unsetenv(t, "MYAPP_PORT", "MYAPP_NAME", "MYAPP_ENV_NAME")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			var port int
+			var name string
+			loader := NewConfigLoader(WithEnvPrefix("MYAPP"), WithConfigPaths(dir))
+			cfg, err := loader.LoadConfig(append(tt.loadOpts, WithArgs(nil)), func(fs *pflag.FlagSet) {
+				fs.IntVar(&port, "port", 80, "Port")
+				fs.StringVar(&name, "name", "default", "Name")
+			})
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			if port != tt.wantPort {
+				t.Errorf("port = %v, want %v", port, tt.wantPort)
+			}
+			if name != tt.wantName {
+				t.Errorf("name = %v, want %v", name, tt.wantName)
+			}
+			if cfg.EnvName != tt.wantEnv {
+				t.Errorf("EnvName = %v, want %v", cfg.EnvName, tt.wantEnv)
+			}
+		})
+	}
+}
+
+func TestConfigLoader_Reload_Dotenv(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, ".env", "MYAPP_PORT=1000\nMYAPP_NAME=dotenv\n")
+	t.Chdir(dir)
+	unsetenv(t, "MYAPP_PORT", "MYAPP_NAME")
+
+	var port int
+	var name string
+	loader := NewConfigLoader(WithEnvPrefix("MYAPP"))
+	if _, err := loader.LoadConfig([]LoadOption{WithDotenv(true), WithArgs(nil)}, func(fs *pflag.FlagSet) {
+		fs.IntVar(&port, "port", 80, "Port")
+		fs.StringVar(&name, "name", "default", "Name")
+	}); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	entries := loader.Effective()
+	for _, e := range entries {
+		if e.Key == "port" && e.Origin != "MYAPP_PORT (.env)" {
+			t.Errorf("port origin = %v, want MYAPP_PORT (.env)", e.Origin)
+		}
+	}
+
+	writeConfigFile(t, dir, ".env", "MYAPP_PORT=2000\n")
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if port != 2000 {
+		t.Errorf("port = %v, want 2000", port)
+	}
+	if name != "default" {
+		t.Errorf("name = %v, want default after removal from .env", name)
+	}
+	if _, ok := os.LookupEnv("MYAPP_NAME"); ok {
+		t.Error("MYAPP_NAME is still set after removal from .env")
+	}
+}
+
+func TestConfigLoader_LoadConfig_DotenvProcessEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, ".env", "MYAPP_PORT=1000\nMYAPP_HOST=example.com\nMYAPP_TOKEN=secret\n")
+	writeConfigFile(t, dir, "config.local.toml", "url = \"https://${MYAPP_HOST}\"\ntoken = \"env://MYAPP_TOKEN\"\n")
+	t.Chdir(dir)
+	unsetenv(t, "MYAPP_PORT", "MYAPP_HOST", "MYAPP_TOKEN", "MYAPP_URL")
+
+	var port int
+	var url, token string
+	loader := NewConfigLoader(WithEnvPrefix("MYAPP"), WithConfigPaths(dir))
+	if _, err := loader.LoadConfig([]LoadOption{WithDotenv(true), WithConfigFile(true), WithArgs(nil)}, func(fs *pflag.FlagSet) {
+		fs.IntVar(&port, "port", 80, "Port")
+		fs.StringVar(&url, "url", "", "URL")
+		fs.StringVar(&token, "token", "", "Token")
+	}); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if port != 1000 || url != "https://example.com" || token != "secret" {
+		t.Errorf("port, url, token = %v, %v, %v, want the values of .env", port, url, token)
+	}
+	for _, key := range []string{"MYAPP_PORT", "MYAPP_HOST", "MYAPP_TOKEN"} {
+		if _, ok := os.LookupEnv(key); ok {
+			t.Errorf("%s is set in the process environment", key)
+		}
+	}
+}
+
+func TestConfigLoader_LoadConfig_DotenvMalformed(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, ".env", "MYAPP_PORT\n")
+	t.Chdir(dir)
+
+	_, err := NewConfigLoader().LoadConfig([]LoadOption{WithDotenv(true), WithArgs(nil)})
+	if err == nil {
+		t.Fatal("LoadConfig() expected error for malformed .env file")
+	}
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	ValidateConfig = "validate-config"
+)
+
+var (
+	// ErrConfigValid is returned by LoadConfig after --validate-config found
+	// the configuration valid, so the caller can exit.
+	ErrConfigValid = errors.New("configuration is valid")
+
+	// ErrConfigInvalid is returned by LoadConfig, along with every problem
+	// found, when --validate-config found the configuration invalid.
+	ErrConfigInvalid = errors.New("configuration is invalid")
+)
+
+// WithValidateConfig adds a --validate-config flag which, when set, runs the
+// whole loading pipeline without using the result: config files, providers
+// and environment variables are read, merged and applied to every flag, and
+// the validators run. LoadConfig then returns ErrConfigValid, or every problem
+// found at once wrapped with ErrConfigInvalid, so the caller can exit with the
+// right status. It is meant to check config files in CI, e.g. with
+// "--validate-config --env-name prod" for each environment.
+func WithValidateConfig(enabled bool) LoadOption {
+	return func(o *loadOptions) {
+		o.validateConfig = enabled
+	}
+}
+
+// dryRun runs the steps of LoadConfig following the parsing of the flags,
+// and returns the problems they found instead of stopping at the first one.
+func (cl *ConfigLoader) dryRun(fs *pflag.FlagSet, config *Config, opts *loadOptions, defaults map[string]any) error {
+	var errs []error
+
+	if _, err := cl.resolveFlagReferences(fs); err != nil {
+		errs = append(errs, fmt.Errorf("failed to apply configuration values: %w", err))
+	}
+
+	v, files, err := cl.readConfig(fs, opts)
+	errs = append(errs, err)
+
+	if opts.strict {
+		errs = append(errs, cl.checkUnknownKeys(fs, files))
+	}
+
+	deprecations, err := cl.collectDeprecations(fs, opts.args, files)
+	errs = append(errs, err)
+	cl.reportDeprecations(deprecations, nil)
+
+	// without the configuration read, the flags keep their values from the
+	// command line, which are still validated
+	if v != nil {
+		if _, err := cl.applyFlagValues(v, fs, files, defaults); err != nil {
+			errs = append(errs, fmt.Errorf("failed to apply configuration values: %w", err))
+		}
+	}
+
+	if err := validate(fs, config, opts, cl.registered()); err != nil {
+		errs = append(errs, fmt.Errorf("configuration validation failed: %w", err))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%w:\n%w", ErrConfigInvalid, err)
+	}
+
+	_, _ = fmt.Fprintf(cl.stdout, "Configuration of environment '%s' is valid\n", config.EnvName)
+	return ErrConfigValid
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestConfigLoader_LoadConfig_ValidateConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		file     string
+		strict   bool
+		wantErr  error
+		wantErrs []string
+		wantOut  string
+	}{
+		{
+			name:    "valid",
+			args:    []string{"--validate-config", "--env-name", "prod"},
+			file:    "port = 8080\n",
+			wantErr: ErrConfigValid,
+			wantOut: "Configuration of environment 'prod' is valid\n",
+		},
+		{
+			name:    "not validating",
+			args:    []string{"--env-name", "prod"},
+			file:    "port = 8080\n",
+			wantErr: nil,
+		},
+		{
+			name:    "every problem",
+			args:    []string{"--validate-config", "--env-name", "prod"},
+			file:    "port = \"abc\"\nworkers = 0\nold-port = 1\nprot = 1\n",
+			strict:  true,
+			wantErr: ErrConfigInvalid,
+			wantErrs: []string{
+				"unknown configuration key 'prot'",
+				"invalid value for 'port'",
+				"workers must be positive",
+			},
+		},
+		{
+			name:     "not strict",
+			args:     []string{"--validate-config", "--env-name", "prod"},
+			file:     "workers = 0\nprot = 1\n",
+			wantErr:  ErrConfigInvalid,
+			wantErrs: []string{"workers must be positive"},
+		},
+		{
+			name:     "missing config file",
+			args:     []string{"--validate-config", "--env-name", "staging"},
+			wantErr:  ErrConfigFileNotFound,
+			wantErrs: []string{"configuration is invalid"},
+		},
+		{
+			name:    "unreadable config and references",
+			args:    []string{"--validate-config", "--env-name", "prod", "--workers", "0", "--token", "file:///missing/token"},
+			file:    "port = \n",
+			wantErr: ErrConfigInvalid,
+			wantErrs: []string{
+				"invalid value for 'token'",
+				"failed to read config file",
+				"workers must be positive",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.file != "" {
+				writeConfigFile(t, dir, "config.prod.toml", tt.file)
+			}
+			unsetenv(t, "PORT", "OLD_PORT", "WORKERS", "ENV_NAME")
+
+			var out bytes.Buffer
+			loader := NewConfigLoader(WithConfigPaths(dir))
+			loader.stdout = &out
+
+			var port, workers int
+			_, err := loader.LoadConfig(
+				[]LoadOption{
+					WithArgs(tt.args),
+					WithConfigFile(true),
+					WithValidateConfig(true),
+					WithStrict(tt.strict),
+					WithValidators(func() error {
+						if workers <= 0 {
+							return fmt.Errorf("workers must be positive, got %d", workers)
+						}
+						return nil
+					}),
+				},
+				aliasFlags(t, &port),
+				func(fs *pflag.FlagSet) {
+					fs.IntVar(&workers, "workers", 4, "Workers")
+					fs.String("token", "", "Token")
+				},
+			)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LoadConfig() error = %v, want %v", err, tt.wantErr)
+			}
+
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("LoadConfig() error = %v, want it to contain %q", err, want)
+				}
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("output = %q, want %q", got, tt.wantOut)
+			}
+		})
+	}
+}
@@ -1,14 +1,22 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 
 	"github.com/alexferl/golib/config"
-	"github.com/spf13/pflag"
 )
 
+// Settings holds the application specific configuration.
+// Flags, environment variables and config file keys are derived from the fields.
+type Settings struct {
+	Port    int  `default:"8080" usage:"Server port" validate:"min=1,max=65535"`
+	Debug   bool `usage:"Enable debug mode"`
+	Version bool `usage:"Show version"`
+}
+
 func main() {
 	fmt.Println("Try these commands:")
 	fmt.Println("  go run main.go")
@@ -16,6 +24,8 @@ func main() {
 	fmt.Println("  MYAPP_APP_NAME=webapi MYAPP_ENV_NAME=staging go run main.go")
 	fmt.Println("  go run main.go --env-name prod  # loads config.prod.toml")
 	fmt.Println("  go run main.go --env-name dev   # loads config.dev.toml")
+	fmt.Println("  go run main.go --env-name prod --print-config")
+	fmt.Println("  go run main.go --env-name prod --validate-config")
 	fmt.Println("  go run main.go --help")
 	fmt.Println()
 
@@ -25,23 +35,27 @@ func main() {
 		config.WithConfigType("toml"),
 	)
 
-	var port int
-	var debug bool
-	var version bool
+	settings := &Settings{}
 
-	cfg, err := loader.LoadConfig(
-		[]config.LoadOption{config.WithConfigFile(true)},
-		func(fs *pflag.FlagSet) {
-			fs.IntVar(&port, "port", 8080, "Server port")
-			fs.BoolVar(&debug, "debug", false, "Enable debug mode")
-			fs.BoolVar(&version, "version", false, "Show version")
-		},
+	cfg, err := loader.LoadInto(settings,
+		config.WithConfigFile(true),
+		config.WithDotenv(true),
+		config.WithPrintConfig(true),
+		config.WithValidateConfig(true),
 	)
 	if err != nil {
+		var helpErr *config.HelpError
+		switch {
+		case errors.As(err, &helpErr):
+			fmt.Print(helpErr.Usage)
+			os.Exit(0)
+		case errors.Is(err, config.ErrConfigPrinted), errors.Is(err, config.ErrConfigValid):
+			os.Exit(0)
+		}
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	if version {
+	if settings.Version {
 		fmt.Println("Version: 1.0.0")
 		os.Exit(0)
 	}
@@ -49,21 +63,21 @@ func main() {
 	fmt.Printf("\nConfiguration loaded:\n")
 	fmt.Printf("  App Name: %s\n", cfg.AppName)
 	fmt.Printf("  Environment: %s\n", cfg.EnvName)
-	fmt.Printf("  Port: %d\n", port)
-	fmt.Printf("  Debug: %t\n", debug)
+	fmt.Printf("  Port: %d\n", settings.Port)
+	fmt.Printf("  Debug: %t\n", settings.Debug)
 	fmt.Println()
 
 	fmt.Println("Configuration sources (in precedence order):")
 	fmt.Println("  1. Command line flags (highest)")
 	fmt.Println("  2. Environment variables (MYAPP_*)")
-	fmt.Printf("  3. Config file (config.%s.toml)\n", cfg.EnvName)
+	fmt.Printf("  3. Config files (config.toml < config.%[1]s.toml < config.%[1]s.local.toml)\n", cfg.EnvName)
 	fmt.Println("  4. Default values (lowest)")
 	fmt.Println()
 
 	fmt.Printf("Starting %s server in %s environment on port %d\n",
-		cfg.AppName, cfg.EnvName, port)
+		cfg.AppName, cfg.EnvName, settings.Port)
 
-	if debug {
+	if settings.Debug {
 		fmt.Println("🐛 Debug mode is enabled")
 	}
 
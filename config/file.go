@@ -0,0 +1,210 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// BaseConfigFileName is the name of the config file holding the settings
+	// shared by every environment.
+	BaseConfigFileName = "config"
+
+	// LocalConfigFileSuffix is appended to a config file name to get the name
+	// of its local overrides file, which is meant to be kept out of version control.
+	LocalConfigFileSuffix = ".local"
+)
+
+// ConfigTypes lists the config file types looked up, by extension, when no
+// config type is set with WithConfigType.
+var ConfigTypes = []string{"toml", "yaml", "yml", "json"}
+
+var (
+	// ErrConfigFileNotFound is returned when a required config file doesn't
+	// exist in any of the config paths.
+	ErrConfigFileNotFound = errors.New("config file not found")
+
+	// ErrAmbiguousConfigFile is returned when a config path holds the same
+	// config file in more than one format.
+	ErrAmbiguousConfigFile = errors.New("config file exists in multiple formats")
+)
+
+// configLayer is a config file name, without extension, to load.
+type configLayer struct {
+	name     string
+	required bool
+}
+
+// configFile holds the values read from a config file or a provider, in
+// which case path is the name of the provider, and the deprecated keys
+// it used.
+type configFile struct {
+	path    string
+	values  map[string]any
+	source  Source
+	aliases []string
+
+	// secret is set for providers whose values are all secrets.
+	secret bool
+}
+
+// configLayers returns the config files to load, from lowest to highest
+// precedence. By default, these are:
+//
+//	config.<ext>              settings shared by every environment (optional)
+//	config.<env>.<ext>        settings for the environment
+//	config.<env>.local.<ext>  local overrides, not version controlled (optional)
+//
+// where <ext> is the config type, or any of ConfigTypes when it isn't set.
+// When a custom config file name is set, it replaces the environment file
+// and the base file isn't loaded.
+func configLayers(v *viper.Viper, opts *loadOptions) []configLayer {
+	name := opts.configFileName
+	if name == "" {
+		envName := v.GetString(EnvName)
+		name = fmt.Sprintf("%s.%s", BaseConfigFileName, strings.ToLower(envName))
+	}
+
+	var layers []configLayer
+	if opts.configFileName == "" {
+		layers = append(layers, configLayer{name: BaseConfigFileName})
+	}
+
+	return append(layers,
+		configLayer{name: name, required: true},
+		configLayer{name: name + LocalConfigFileSuffix},
+	)
+}
+
+// readConfigFiles reads the config file of every layer found in the config
+// paths. Tables are merged deeply by later layers, while any other value,
+// including arrays, is replaced as a whole.
+func (cl *ConfigLoader) readConfigFiles(layers []configLayer) ([]configFile, error) {
+	var files []configFile
+
+	for _, layer := range layers {
+		path, err := cl.findConfigFile(layer.name)
+		if err != nil {
+			return nil, err
+		}
+		if path == "" {
+			if layer.required {
+				return nil, fmt.Errorf("%w: '%s.%s' in paths %v",
+					ErrConfigFileNotFound, layer.name, cl.fileExtensions(), cl.configPaths)
+			}
+			continue
+		}
+
+		values, err := cl.readConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, configFile{path: path, values: values, source: SourceFile})
+	}
+
+	return files, nil
+}
+
+// findConfigFile returns the path of the config file with the given name in
+// the first config path that contains it, or an empty path if none does.
+func (cl *ConfigLoader) findConfigFile(name string) (string, error) {
+	for _, dir := range cl.configPaths {
+		var found []string
+		for _, path := range cl.configFilePathsIn(dir, name) {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				found = append(found, path)
+			}
+		}
+
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return found[0], nil
+		default:
+			return "", fmt.Errorf("%w: '%s', keep only one of them",
+				ErrAmbiguousConfigFile, strings.Join(found, "', '"))
+		}
+	}
+	return "", nil
+}
+
+// configFilePaths returns every path the config file with the given name
+// may be found at.
+func (cl *ConfigLoader) configFilePaths(name string) []string {
+	var paths []string
+	for _, dir := range cl.configPaths {
+		paths = append(paths, cl.configFilePathsIn(dir, name)...)
+	}
+	return paths
+}
+
+// configFilePathsIn returns the path of the config file with the given name
+// in dir for every config type.
+func (cl *ConfigLoader) configFilePathsIn(dir, name string) []string {
+	types := ConfigTypes
+	if cl.configType != "" {
+		types = []string{cl.configType}
+	}
+
+	paths := make([]string, len(types))
+	for i, configType := range types {
+		paths[i] = filepath.Join(dir, name+"."+configType)
+	}
+	return paths
+}
+
+// fileExtensions describes the config file extensions looked up, e.g.
+// "toml" or "{toml,yaml,yml,json}".
+func (cl *ConfigLoader) fileExtensions() string {
+	if cl.configType != "" {
+		return cl.configType
+	}
+	return "{" + strings.Join(ConfigTypes, ",") + "}"
+}
+
+// readConfigFile reads the values of a single config file.
+func (cl *ConfigLoader) readConfigFile(path string) (map[string]any, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType(strings.TrimPrefix(filepath.Ext(path), "."))
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file '%s': %w", path, err)
+	}
+
+	return v.AllSettings(), nil
+}
+
+// watchedFiles returns the absolute path of every config and .env file that
+// may be part of the configuration, whether it exists or not.
+func (cl *ConfigLoader) watchedFiles() []string {
+	if cl.opts == nil {
+		return nil
+	}
+
+	var paths []string
+	if cl.opts.loadConfigFile {
+		for _, layer := range configLayers(cl.viper, cl.opts) {
+			paths = append(paths, cl.configFilePaths(layer.name)...)
+		}
+	}
+	if cl.opts.dotenv {
+		envName := strings.ToLower(cl.viper.GetString(EnvName))
+		paths = append(paths, DotenvFileName, DotenvFileName+"."+envName)
+	}
+
+	abs := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if p, err := filepath.Abs(path); err == nil {
+			abs = append(abs, p)
+		}
+	}
+	return abs
+}
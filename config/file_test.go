@@ -0,0 +1,235 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestConfigLoader_LoadConfig_Layers(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		os.Args = originalArgs
+	}()
+
+	type values struct {
+		name    string
+		port    int
+		debug   bool
+		origins []string
+		labels  map[string]string
+	}
+
+	tests := []struct {
+		name       string
+		files      map[string]string
+		options    []Option
+		loadOpts   []LoadOption
+		wantErr    error
+		errContain string
+		validate   func(t *testing.T, v *values)
+	}{
+		{
+			name: "base, environment and local files are merged",
+			files: map[string]string{
+				"config.toml": `name = "base"
+port = 8080
+origins = ["https://a.example.com", "https://b.example.com"]
+
+[labels]
+team = "core"
+tier = "backend"
+`,
+				"config.test.toml": `port = 9090
+origins = ["https://c.example.com"]
+
+[labels]
+tier = "frontend"
+`,
+				"config.test.local.toml": `debug = true`,
+			},
+			loadOpts: []LoadOption{WithConfigFile(true)},
+			validate: func(t *testing.T, v *values) {
+				if v.name != "base" {
+					t.Errorf("name = %v, want base", v.name)
+				}
+				if v.port != 9090 {
+					t.Errorf("port = %v, want 9090", v.port)
+				}
+				if !v.debug {
+					t.Errorf("debug = %v, want true", v.debug)
+				}
+				if len(v.origins) != 1 || v.origins[0] != "https://c.example.com" {
+					t.Errorf("origins = %v, want arrays to be replaced", v.origins)
+				}
+				if v.labels["team"] != "core" || v.labels["tier"] != "frontend" {
+					t.Errorf("labels = %v, want tables to be merged", v.labels)
+				}
+			},
+		},
+		{
+			name: "environment file without base file",
+			files: map[string]string{
+				"config.test.toml": `port = 9090`,
+			},
+			loadOpts: []LoadOption{WithConfigFile(true)},
+			validate: func(t *testing.T, v *values) {
+				if v.port != 9090 {
+					t.Errorf("port = %v, want 9090", v.port)
+				}
+				if v.name != "default" {
+					t.Errorf("name = %v, want default", v.name)
+				}
+			},
+		},
+		{
+			name: "missing environment file",
+			files: map[string]string{
+				"config.toml": `port = 9090`,
+			},
+			loadOpts: []LoadOption{WithConfigFile(true)},
+			wantErr:  ErrConfigFileNotFound,
+		},
+		{
+			name: "custom file skips base file",
+			files: map[string]string{
+				"config.toml":       `name = "base"`,
+				"custom.toml":       `port = 7070`,
+				"custom.local.toml": `debug = true`,
+			},
+			loadOpts: []LoadOption{WithCustomConfigFile("custom")},
+			validate: func(t *testing.T, v *values) {
+				if v.name != "default" {
+					t.Errorf("name = %v, want default", v.name)
+				}
+				if v.port != 7070 {
+					t.Errorf("port = %v, want 7070", v.port)
+				}
+				if !v.debug {
+					t.Errorf("debug = %v, want true", v.debug)
+				}
+			},
+		},
+		{
+			name: "formats are detected by extension",
+			files: map[string]string{
+				"config.toml": `name = "base"`,
+				"config.test.yaml": `port: 9090
+labels:
+  team: core
+`,
+				"config.test.local.json": `{"debug": true}`,
+			},
+			loadOpts: []LoadOption{WithConfigFile(true)},
+			validate: func(t *testing.T, v *values) {
+				if v.name != "base" {
+					t.Errorf("name = %v, want base", v.name)
+				}
+				if v.port != 9090 {
+					t.Errorf("port = %v, want 9090", v.port)
+				}
+				if !v.debug {
+					t.Errorf("debug = %v, want true", v.debug)
+				}
+				if v.labels["team"] != "core" {
+					t.Errorf("labels = %v, want team=core", v.labels)
+				}
+			},
+		},
+		{
+			name: "yml extension",
+			files: map[string]string{
+				"config.test.yml": `port: 9090`,
+			},
+			loadOpts: []LoadOption{WithConfigFile(true)},
+			validate: func(t *testing.T, v *values) {
+				if v.port != 9090 {
+					t.Errorf("port = %v, want 9090", v.port)
+				}
+			},
+		},
+		{
+			name: "same file in multiple formats",
+			files: map[string]string{
+				"config.test.toml": `port = 9090`,
+				"config.test.yaml": `port: 7070`,
+			},
+			loadOpts:   []LoadOption{WithConfigFile(true)},
+			wantErr:    ErrAmbiguousConfigFile,
+			errContain: "config.test.toml', '",
+		},
+		{
+			name: "config type restricts formats",
+			files: map[string]string{
+				"config.test.toml": `port = 9090`,
+				"config.test.yaml": `port: 7070`,
+			},
+			options:  []Option{WithConfigType("yaml")},
+			loadOpts: []LoadOption{WithConfigFile(true)},
+			validate: func(t *testing.T, v *values) {
+				if v.port != 7070 {
+					t.Errorf("port = %v, want 7070", v.port)
+				}
+			},
+		},
+		{
+			name: "missing file lists extensions",
+			files: map[string]string{
+				"config.toml": `port = 9090`,
+			},
+			loadOpts:   []LoadOption{WithConfigFile(true)},
+			errContain: "'config.test.{toml,yaml,yml,json}'",
+		},
+		{
+			name: "malformed layer",
+			files: map[string]string{
+				"config.toml":      `port = `,
+				"config.test.toml": `port = 9090`,
+			},
+			loadOpts:   []LoadOption{WithConfigFile(true)},
+			errContain: "failed to read config file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeConfigFile(t, dir, name, content)
+			}
+
+			os.Args = []string{"testapp", "--env-name", "test"}
+
+			v := &values{}
+			loader := NewConfigLoader(append([]Option{WithConfigPaths(dir)}, tt.options...)...)
+			_, err := loader.LoadConfig(tt.loadOpts, func(fs *pflag.FlagSet) {
+				fs.StringVar(&v.name, "name", "default", "Name")
+				fs.IntVar(&v.port, "port", 80, "Port")
+				fs.BoolVar(&v.debug, "debug", false, "Debug")
+				fs.StringSliceVar(&v.origins, "origins", nil, "Origins")
+				fs.StringToStringVar(&v.labels, "labels", nil, "Labels")
+			})
+
+			if tt.wantErr != nil || tt.errContain != "" {
+				if err == nil {
+					t.Fatal("LoadConfig() expected error but got nil")
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("LoadConfig() error = %v, want %v", err, tt.wantErr)
+				}
+				if tt.errContain != "" && !strings.Contains(err.Error(), tt.errContain) {
+					t.Errorf("LoadConfig() error = %v, want to contain %v", err, tt.errContain)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			tt.validate(t, v)
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/pflag"
+)
+
+var (
+	// ErrUndefinedVariable is returned when a config value references an
+	// environment variable that isn't set and has no default value.
+	ErrUndefinedVariable = errors.New("undefined variable")
+
+	// ErrInterpolationCycle is returned when config values reference each
+	// other in a cycle.
+	ErrInterpolationCycle = errors.New("interpolation cycle")
+)
+
+// interpolator expands the references of config file values:
+//
+//	${name}           the value of the key name, or of the environment variable name
+//	${name:-default}  the same, or default when it's unset or empty
+//	$${               a literal "${"
+//
+// A name is a key when the flag set has a flag of that name, such as
+// "app-name", and an environment variable otherwise, such as "HOST". Keys
+// resolve to their value by precedence, expanded too if it comes from a
+// config file. Values of providers are used as they are: they come from
+// outside the repository and may hold a literal "${", such as a password.
+type interpolator struct {
+	cl    *ConfigLoader
+	fs    *pflag.FlagSet
+	files []configFile
+
+	// resolved caches the expanded value of keys, and stack holds the keys
+	// being expanded to detect cycles.
+	resolved map[string]string
+	stack    []string
+}
+
+// interpolate expands the references of the values of config files in place,
+// leaving the values of providers as they are. The values are replaced once
+// all of them are expanded, so none is expanded twice.
+func (cl *ConfigLoader) interpolate(fs *pflag.FlagSet, files []configFile) error {
+	in := &interpolator{cl: cl, fs: fs, files: files, resolved: make(map[string]string)}
+
+	expanded := make([]map[string]any, len(files))
+	var errs []error
+	for i, file := range files {
+		if file.source != SourceFile {
+			expanded[i] = file.values
+			continue
+		}
+
+		expanded[i] = make(map[string]any, len(file.values))
+		for key, value := range file.values {
+			e, err := in.expandValue(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to interpolate '%s' in '%s': %w", key, file.path, err))
+				continue
+			}
+			expanded[i][key] = e
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for i := range files {
+		files[i].values = expanded[i]
+	}
+	return nil
+}
+
+// expandValue expands the strings of a value read from a config file, at any
+// depth of lists and tables.
+func (in *interpolator) expandValue(value any) (any, error) {
+	switch val := value.(type) {
+	case string:
+		return in.expand(val)
+	case []any:
+		expanded := make([]any, len(val))
+		for i, elem := range val {
+			e, err := in.expandValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			expanded[i] = e
+		}
+		return expanded, nil
+	case map[string]any:
+		expanded := make(map[string]any, len(val))
+		for k, elem := range val {
+			e, err := in.expandValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			expanded[k] = e
+		}
+		return expanded, nil
+	default:
+		return value, nil
+	}
+}
+
+// expand returns s with its references replaced.
+func (in *interpolator) expand(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+
+		if i > 0 && s[i-1] == '$' {
+			b.WriteString(s[:i-1])
+			b.WriteString("${")
+			s = s[i+2:]
+			continue
+		}
+
+		end := closingBrace(s, i+2)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated reference '%s'", s[i:])
+		}
+
+		value, err := in.reference(s[i+2 : end])
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(s[:i])
+		b.WriteString(value)
+		s = s[end+1:]
+	}
+}
+
+// reference returns the value of the reference ref, the text between "${"
+// and "}".
+func (in *interpolator) reference(ref string) (string, error) {
+	name, def, hasDefault := strings.Cut(ref, ":-")
+	if name == "" {
+		return "", fmt.Errorf("empty reference '${%s}'", ref)
+	}
+
+	var value string
+	var ok bool
+	var err error
+	if f := in.fs.Lookup(name); f != nil {
+		value, ok, err = in.key(f)
+	} else {
+		value, ok = in.cl.lookupEnv(name)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if ok && value != "" || !hasDefault {
+		if !ok {
+			return "", fmt.Errorf("%w '%s'", ErrUndefinedVariable, name)
+		}
+		return value, nil
+	}
+
+	return in.expand(def)
+}
+
+// key returns the value of the flag f by precedence, and whether it's set.
+// Values of config files are expanded.
+func (in *interpolator) key(f *pflag.Flag) (string, bool, error) {
+	if f.Changed {
+		s, err := interpolationString(flagValue(f))
+		return s, true, err
+	}
+
+	if _, value, _, ok := in.cl.flagEnv(f); ok {
+		return value, true, nil
+	}
+
+	for i := len(in.files) - 1; i >= 0; i-- {
+		value, ok := in.files[i].values[f.Name]
+		if !ok {
+			continue
+		}
+
+		if in.files[i].source != SourceFile {
+			s, err := interpolationString(value)
+			return s, true, err
+		}
+
+		if s, ok := in.resolved[f.Name]; ok {
+			return s, true, nil
+		}
+
+		for j, key := range in.stack {
+			if key == f.Name {
+				cycle := append(in.stack[j:], f.Name)
+				return "", false, fmt.Errorf("%w: %s", ErrInterpolationCycle, strings.Join(cycle, " -> "))
+			}
+		}
+
+		in.stack = append(in.stack, f.Name)
+		expanded, err := in.expandValue(value)
+		in.stack = in.stack[:len(in.stack)-1]
+		if err != nil {
+			return "", false, err
+		}
+
+		s, err := interpolationString(expanded)
+		if err != nil {
+			return "", false, err
+		}
+		in.resolved[f.Name] = s
+		return s, true, nil
+	}
+
+	return f.DefValue, true, nil
+}
+
+// closingBrace returns the index of the "}" closing the reference starting at
+// start, skipping nested references, or -1.
+func closingBrace(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch {
+		case s[i] == '}' && depth == 0:
+			return i
+		case s[i] == '}':
+			depth--
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i++
+		}
+	}
+	return -1
+}
+
+// interpolationString converts a value to the string it's replaced by, with
+// lists joined by commas.
+func interpolationString(value any) (string, error) {
+	switch val := value.(type) {
+	case []string:
+		return strings.Join(val, ","), nil
+	case []any:
+		elems := make([]string, len(val))
+		for i, elem := range val {
+			s, err := cast.ToStringE(elem)
+			if err != nil {
+				return "", err
+			}
+			elems[i] = s
+		}
+		return strings.Join(elems, ","), nil
+	default:
+		return cast.ToStringE(value)
+	}
+}
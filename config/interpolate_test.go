@@ -0,0 +1,150 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestConfigLoader_LoadConfig_Interpolation(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		args        []string
+		env         map[string]string
+		provider    map[string]any
+		wantAddr    string
+		wantURL     string
+		wantOrigins []string
+		wantErr     error
+	}{
+		{
+			name:     "environment variables",
+			file:     "addr = \"${HOST:-0.0.0.0}:${PORT}\"\n",
+			env:      map[string]string{"PORT": "8080"},
+			wantAddr: "0.0.0.0:8080",
+		},
+		{
+			name:     "environment variable overriding default",
+			file:     "addr = \"${HOST:-0.0.0.0}:${PORT:-80}\"\n",
+			env:      map[string]string{"HOST": "127.0.0.1"},
+			wantAddr: "127.0.0.1:80",
+		},
+		{
+			name:     "empty environment variable",
+			file:     "addr = \"${HOST:-localhost}${PORT}\"\n",
+			env:      map[string]string{"HOST": "", "PORT": ""},
+			wantAddr: "localhost",
+		},
+		{
+			name:    "default key",
+			file:    "url = \"https://${app-name}.example.com\"\n",
+			wantURL: "https://app.example.com",
+		},
+		{
+			name:    "key from file",
+			file:    "app-name = \"myapp\"\nurl = \"https://${app-name}.example.com\"\n",
+			wantURL: "https://myapp.example.com",
+		},
+		{
+			name:    "key from env",
+			file:    "app-name = \"myapp\"\nurl = \"https://${app-name}.example.com\"\n",
+			env:     map[string]string{"APP_NAME": "envapp"},
+			wantURL: "https://envapp.example.com",
+		},
+		{
+			name:    "key from flag",
+			file:    "url = \"https://${app-name}.example.com\"\n",
+			args:    []string{"--app-name", "flagapp"},
+			wantURL: "https://flagapp.example.com",
+		},
+		{
+			name:     "chained keys",
+			file:     "addr = \"${HOST:-localhost}:8080\"\nurl = \"http://${addr}\"\n",
+			wantAddr: "localhost:8080",
+			wantURL:  "http://localhost:8080",
+		},
+		{
+			name:    "nested default",
+			file:    "url = \"${URL:-http://${HOST:-localhost}}\"\n",
+			wantURL: "http://localhost",
+		},
+		{
+			name:    "escaped",
+			file:    "url = \"$${HOST} costs $5\"\n",
+			wantURL: "${HOST} costs $5",
+		},
+		{
+			name:        "lists",
+			file:        "addr = \"a:1\"\norigins = [\"https://${addr}\", \"${HOST:-b}\"]\n",
+			wantAddr:    "a:1",
+			wantOrigins: []string{"https://a:1", "b"},
+		},
+		{
+			name:    "undefined variable",
+			file:    "addr = \"${HOST}:${PORT}\"\n",
+			wantErr: ErrUndefinedVariable,
+		},
+		{
+			name:    "cycle",
+			file:    "addr = \"${url}\"\nurl = \"http://${addr}\"\n",
+			wantErr: ErrInterpolationCycle,
+		},
+		{
+			name:    "self reference",
+			file:    "addr = \"${addr}:80\"\n",
+			wantErr: ErrInterpolationCycle,
+		},
+		{
+			name:     "provider values aren't expanded",
+			file:     "url = \"https://${addr}\"\n",
+			provider: map[string]any{"addr": "p@ss${word"},
+			wantAddr: "p@ss${word",
+			wantURL:  "https://p@ss${word",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeConfigFile(t, dir, "config.local.toml", tt.file)
+
+			unsetenv(t, "HOST", "PORT", "URL", "APP_NAME", "ADDR", "ORIGINS")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			var addr, url string
+			var origins []string
+			loader := NewConfigLoader(WithConfigPaths(dir), WithProviders(&staticProvider{values: tt.provider}))
+			_, err := loader.LoadConfig(
+				[]LoadOption{WithArgs(tt.args), WithConfigFile(true)},
+				func(fs *pflag.FlagSet) {
+					fs.StringVar(&addr, "addr", "", "Address")
+					fs.StringVar(&url, "url", "", "URL")
+					fs.StringSliceVar(&origins, "origins", nil, "Origins")
+				},
+			)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LoadConfig() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if addr != tt.wantAddr {
+				t.Errorf("addr = %v, want %v", addr, tt.wantAddr)
+			}
+			if url != tt.wantURL {
+				t.Errorf("url = %v, want %v", url, tt.wantURL)
+			}
+			if len(origins) > 0 || len(tt.wantOrigins) > 0 {
+				if !reflect.DeepEqual(origins, tt.wantOrigins) {
+					t.Errorf("origins = %v, want %v", origins, tt.wantOrigins)
+				}
+			}
+		})
+	}
+}
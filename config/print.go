@@ -0,0 +1,233 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	PrintConfig = "print-config"
+)
+
+// Formats supported by Print and the --print-config flag.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatTOML = "toml"
+)
+
+var printFormats = []string{FormatText, FormatJSON, FormatTOML}
+
+// internalAnnotation marks flags that control the loader rather than hold configuration.
+const internalAnnotation = "config_internal"
+
+// sensitiveKeyWords are the words that make a key's value redacted when shown.
+var sensitiveKeyWords = []string{"secret", "password", "token", "key"}
+
+// referenceKeyWords are the last words of keys naming where a secret is
+// found, such as "tls-key-file", whose value isn't redacted.
+var referenceKeyWords = []string{"file", "lookup"}
+
+// Source is where the value of a configuration key comes from.
+type Source string
+
+const (
+	SourceDefault  Source = "default"
+	SourceFile     Source = "file"
+	SourceProvider Source = "provider"
+	SourceEnv      Source = "env"
+	SourceFlag     Source = "flag"
+)
+
+// Entry is a resolved configuration key.
+type Entry struct {
+	// Key is the flag name, which is also the config file key.
+	Key string `json:"key"`
+
+	// Value is the resolved value, or Redacted for secrets.
+	Value any `json:"value"`
+
+	// Source is where the value comes from.
+	Source Source `json:"source"`
+
+	// Origin is the flag, environment variable, file or provider the value
+	// comes from.
+	Origin string `json:"origin,omitempty"`
+}
+
+// ErrConfigPrinted is returned by LoadConfig after printing the configuration
+// requested with --print-config, so the caller can exit.
+var ErrConfigPrinted = errors.New("configuration printed")
+
+// WithPrintConfig adds a --print-config flag which, when set, prints the
+// resolved configuration in the given format (text, json or toml) and makes
+// LoadConfig return ErrConfigPrinted.
+func WithPrintConfig(enabled bool) LoadOption {
+	return func(o *loadOptions) {
+		o.printConfig = enabled
+	}
+}
+
+// Effective returns every resolved configuration key with its value and
+// where it comes from, sorted by key. Values of secrets and of keys that look
+// sensitive (containing secret, password, token or key) are redacted.
+func (cl *ConfigLoader) Effective() []Entry {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if cl.flagSet == nil {
+		return nil
+	}
+
+	return cl.effective(cl.flagSet, cl.viper, cl.files, cl.secrets)
+}
+
+// Print writes the resolved configuration to w in the given format.
+func (cl *ConfigLoader) Print(w io.Writer, format string) error {
+	return printEntries(w, cl.Effective(), format)
+}
+
+// effective returns the resolved configuration keys of fs.
+func (cl *ConfigLoader) effective(fs *pflag.FlagSet, v *viper.Viper, files []configFile, secrets map[string]bool) []Entry {
+	var entries []Entry
+
+	fs.VisitAll(func(f *pflag.Flag) {
+		if _, ok := f.Annotations[internalAnnotation]; ok {
+			return
+		}
+
+		entry := Entry{Key: f.Name, Value: typedFlagValue(f)}
+		entry.Source, entry.Origin = cl.source(f, v, files)
+
+		if secrets[f.Name] || (isSensitiveKey(f.Name) && f.Value.String() != "") {
+			entry.Value = Redacted
+		}
+
+		entries = append(entries, entry)
+	})
+
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return entries
+}
+
+// source returns where the value of the flag comes from.
+func (cl *ConfigLoader) source(f *pflag.Flag, v *viper.Viper, files []configFile) (Source, string) {
+	if f.Changed {
+		return SourceFlag, "--" + f.Name
+	}
+
+	if !v.IsSet(f.Name) {
+		return SourceDefault, ""
+	}
+
+	if env, _, file, ok := cl.flagEnv(f); ok {
+		if file != "" {
+			return SourceEnv, fmt.Sprintf("%s (%s)", env, file)
+		}
+		return SourceEnv, env
+	}
+
+	for i := len(files) - 1; i >= 0; i-- {
+		if _, ok := files[i].values[f.Name]; ok {
+			return files[i].source, files[i].path
+		}
+	}
+
+	return SourceDefault, ""
+}
+
+// envNames returns the environment variables that set the flag, by precedence.
+func (cl *ConfigLoader) envNames(f *pflag.Flag) []string {
+	if envs, ok := f.Annotations[envAnnotation]; ok {
+		return envs
+	}
+	return []string{cl.envName(f.Name)}
+}
+
+// envName returns the environment variable derived from key.
+func (cl *ConfigLoader) envName(key string) string {
+	name := strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+	if cl.envVarPrefix != "" {
+		name = strings.ToUpper(cl.envVarPrefix) + "_" + name
+	}
+	return name
+}
+
+// isSensitiveKey reports whether the key looks like it holds a secret: one
+// of its words, separated by "-", "_" or ".", is a sensitive word and its
+// last word isn't a reference word.
+func isSensitiveKey(key string) bool {
+	words := strings.FieldsFunc(strings.ToLower(key), func(r rune) bool {
+		return r == '-' || r == '_' || r == '.'
+	})
+	if len(words) == 0 || slices.Contains(referenceKeyWords, words[len(words)-1]) {
+		return false
+	}
+
+	return slices.ContainsFunc(words, func(word string) bool {
+		return slices.Contains(sensitiveKeyWords, word)
+	})
+}
+
+// typedFlagValue returns the value of the flag as a bool, number, list or string.
+func typedFlagValue(f *pflag.Flag) any {
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		return slices.Clone(sv.GetSlice())
+	}
+
+	if strings.HasPrefix(f.Value.Type(), "stringTo") {
+		return flagValue(f)
+	}
+
+	return typedValue(f.Value.Type(), f.Value.String())
+}
+
+// printEntries writes entries to w in the given format.
+func printEntries(w io.Writer, entries []Entry, format string) error {
+	switch strings.ToLower(format) {
+	case FormatText:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+		for _, e := range entries {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", e.Key, formatFlagValue(e.Value), formatSource(e))
+		}
+		return tw.Flush()
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case FormatTOML:
+		for _, e := range entries {
+			b, err := toml.Marshal(map[string]any{e.Key: e.Value})
+			if err != nil {
+				return fmt.Errorf("failed to encode '%s': %w", e.Key, err)
+			}
+			if _, err := fmt.Fprintf(w, "# %s\n%s", formatSource(e), b); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid format '%s', must be one of: %s", format, strings.Join(printFormats, ", "))
+	}
+}
+
+// formatSource formats the source of an entry, e.g. "env (MYAPP_APP_NAME)".
+func formatSource(e Entry) string {
+	if e.Origin == "" {
+		return string(e.Source)
+	}
+	return fmt.Sprintf("%s (%s)", e.Source, e.Origin)
+}
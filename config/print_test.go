@@ -0,0 +1,218 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type printTest struct {
+	loader     *ConfigLoader
+	out        *bytes.Buffer
+	err        error
+	configFile string
+}
+
+func loadPrintTest(t *testing.T, args []string, loadOpts ...LoadOption) *printTest {
+	t.Helper()
+
+	dir := t.TempDir()
+	configFile := writeConfigFile(t, dir, "config.test.toml", `write-timeout = "30s"
+port = 9090
+session-secret = "changeme"
+`)
+	t.Setenv("TEST_BIND_ADDR", "0.0.0.0:80")
+	t.Setenv("TEST_API_TOKEN", "base64:dDBrZW4tdmFsdWU=")
+	pt := &printTest{out: &bytes.Buffer{}, configFile: configFile}
+
+	loader := NewConfigLoader(WithEnvPrefix("TEST"), WithConfigPaths(dir))
+	loader.stdout = pt.out
+	pt.loader = loader
+
+	loadOpts = append([]LoadOption{
+		WithConfigFile(true),
+		WithArgs(append([]string{"--env-name", "test"}, args...)),
+	}, loadOpts...)
+	_, pt.err = loader.LoadConfig(loadOpts, func(fs *pflag.FlagSet) {
+		fs.String("bind-addr", "localhost:8080", "Bind address")
+		fs.Duration("write-timeout", 10*time.Second, "Write timeout")
+		fs.Int("port", 80, "Port")
+		fs.Bool("debug", false, "Debug")
+		fs.StringSlice("origins", []string{"*"}, "Origins")
+		fs.String("session-secret", "", "Session secret")
+		fs.String("api-token", "", "API token")
+		fs.String("password", "", "Password")
+	})
+	if pt.err != nil && !errors.Is(pt.err, ErrConfigPrinted) {
+		t.Fatalf("LoadConfig() error = %v", pt.err)
+	}
+
+	return pt
+}
+
+func TestConfigLoader_Effective(t *testing.T) {
+	pt := loadPrintTest(t, []string{"--debug"})
+	configFile := pt.configFile
+
+	want := map[string]Entry{
+		"app-name":       {Key: "app-name", Value: DefaultAppName, Source: SourceDefault},
+		"env-name":       {Key: "env-name", Value: "test", Source: SourceFlag, Origin: "--env-name"},
+		"bind-addr":      {Key: "bind-addr", Value: "0.0.0.0:80", Source: SourceEnv, Origin: "TEST_BIND_ADDR"},
+		"write-timeout":  {Key: "write-timeout", Value: "30s", Source: SourceFile, Origin: configFile},
+		"port":           {Key: "port", Value: int64(9090), Source: SourceFile, Origin: configFile},
+		"debug":          {Key: "debug", Value: true, Source: SourceFlag, Origin: "--debug"},
+		"session-secret": {Key: "session-secret", Value: Redacted, Source: SourceFile, Origin: configFile},
+		"api-token":      {Key: "api-token", Value: Redacted, Source: SourceEnv, Origin: "TEST_API_TOKEN"},
+		"password":       {Key: "password", Value: "", Source: SourceDefault},
+	}
+
+	entries := pt.loader.Effective()
+	if len(entries) != len(want)+1 {
+		t.Errorf("Effective() returned %d entries, want %d", len(entries), len(want)+1)
+	}
+
+	for i, e := range entries {
+		if i > 0 && entries[i-1].Key > e.Key {
+			t.Errorf("entries are not sorted: %s before %s", entries[i-1].Key, e.Key)
+		}
+
+		if e.Key == "origins" {
+			if v, ok := e.Value.([]string); !ok || len(v) != 1 || v[0] != "*" {
+				t.Errorf("origins = %#v, want [*]", e.Value)
+			}
+			continue
+		}
+
+		w, ok := want[e.Key]
+		if !ok {
+			t.Errorf("unexpected entry %v", e)
+			continue
+		}
+		if e != w {
+			t.Errorf("entry = %#v, want %#v", e, w)
+		}
+	}
+}
+
+func TestConfigLoader_PrintConfig(t *testing.T) {
+	loader := loadPrintTest(t, nil).loader
+
+	tests := []struct {
+		format   string
+		contains []string
+		wantErr  bool
+	}{
+		{
+			format: FormatText,
+			contains: []string{
+				"KEY",
+				"bind-addr       0.0.0.0:80",
+				"env (TEST_BIND_ADDR)",
+				"session-secret  " + Redacted,
+			},
+		},
+		{
+			format: FormatTOML,
+			contains: []string{
+				"# env (TEST_BIND_ADDR)\nbind-addr = '0.0.0.0:80'",
+				"port = 9090",
+				"origins = ['*']",
+				"debug = false",
+			},
+		},
+		{
+			format: FormatJSON,
+			contains: []string{
+				`"key": "bind-addr"`,
+				`"value": 9090`,
+				`"source": "default"`,
+			},
+		},
+		{
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			err := loader.Print(&out, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Print() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for _, s := range tt.contains {
+				if !strings.Contains(out.String(), s) {
+					t.Errorf("Print() output = %s, want to contain %s", out.String(), s)
+				}
+			}
+
+			if strings.Contains(out.String(), "changeme") || strings.Contains(out.String(), "t0ken-value") {
+				t.Errorf("Print() output leaks a secret: %s", out.String())
+			}
+		})
+	}
+}
+
+func TestConfigLoader_PrintConfigFlag(t *testing.T) {
+	pt := loadPrintTest(t, []string{"--print-config=json"}, WithPrintConfig(true))
+
+	if !errors.Is(pt.err, ErrConfigPrinted) {
+		t.Errorf("LoadConfig() error = %v, want %v", pt.err, ErrConfigPrinted)
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(pt.out.Bytes(), &entries); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, pt.out.String())
+	}
+
+	for _, e := range entries {
+		if e.Key == PrintConfig {
+			t.Error("print-config flag should not be printed")
+		}
+	}
+}
+
+func TestConfigLoader_PrintConfigFlag_NotSet(t *testing.T) {
+	pt := loadPrintTest(t, nil, WithPrintConfig(true))
+
+	if pt.err != nil {
+		t.Errorf("LoadConfig() error = %v, want nil", pt.err)
+	}
+	if pt.out.Len() != 0 {
+		t.Errorf("unexpected output: %s", pt.out.String())
+	}
+}
+
+func TestIsSensitiveKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{key: "session-secret", want: true},
+		{key: "db-password", want: true},
+		{key: "api_token", want: true},
+		{key: "server.tls.key", want: true},
+		{key: "API-KEY", want: true},
+		{key: "server-tls-key-file", want: false},
+		{key: "csrf-token-lookup", want: false},
+		{key: "monkey-count", want: false},
+		{key: "tokenizer", want: false},
+		{key: "port", want: false},
+		{key: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := isSensitiveKey(tt.key); got != tt.want {
+				t.Errorf("isSensitiveKey(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
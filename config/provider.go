@@ -0,0 +1,144 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultPollInterval is how often providers that can't be watched are
+	// read again when watching is enabled.
+	DefaultPollInterval = 30 * time.Second
+
+	// providerReadTimeout bounds the time taken to read a provider.
+	providerReadTimeout = 10 * time.Second
+)
+
+// ErrWatchNotSupported is returned by ProviderWatcher.Watch when the provider
+// can't be watched and must be polled instead.
+var ErrWatchNotSupported = errors.New("watch not supported")
+
+// Provider is a remote source of configuration values, such as an HTTP
+// endpoint or a key/value store. Values of providers take precedence over
+// config files and are overridden by environment variables and flags.
+type Provider interface {
+	// Name identifies the provider, e.g. in the effective configuration.
+	Name() string
+
+	// Read returns the configuration values, keyed like config files.
+	Read(ctx context.Context) (map[string]any, error)
+}
+
+// ProviderWatcher is implemented by providers that can notify changes, for
+// example with long polling, rather than being polled.
+type ProviderWatcher interface {
+	Provider
+
+	// Watch blocks until the values may have changed since the last Read, or
+	// ctx is done. It returns ErrWatchNotSupported if the provider must be
+	// polled instead.
+	Watch(ctx context.Context) error
+}
+
+// SecretProvider is implemented by providers whose values may all be secrets,
+// such as a DirProvider reading a Secret volume. When Secret returns true,
+// every key the provider sets is redacted like a resolved reference.
+type SecretProvider interface {
+	Provider
+
+	// Secret reports whether the values of the provider are secrets.
+	Secret() bool
+}
+
+// WithProviders adds providers read, in order, after the config files. Later
+// providers take precedence over earlier ones.
+func WithProviders(providers ...Provider) Option {
+	return func(cl *ConfigLoader) {
+		cl.providers = append(cl.providers, providers...)
+	}
+}
+
+// WithPollInterval sets how often providers that can't be watched are read
+// again when watching is enabled. Defaults to DefaultPollInterval.
+func WithPollInterval(interval time.Duration) Option {
+	return func(cl *ConfigLoader) {
+		cl.pollInterval = interval
+	}
+}
+
+// readProviders reads the values of every provider.
+func (cl *ConfigLoader) readProviders() ([]configFile, error) {
+	var sources []configFile
+
+	for _, p := range cl.providers {
+		ctx, cancel := context.WithTimeout(context.Background(), providerReadTimeout)
+		values, err := p.Read(ctx)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read provider '%s': %w", p.Name(), err)
+		}
+
+		sp, ok := p.(SecretProvider)
+		secret := ok && sp.Secret()
+
+		sources = append(sources, configFile{path: p.Name(), values: values, source: SourceProvider, secret: secret})
+	}
+
+	return sources, nil
+}
+
+// watchProviders starts refreshing the configuration when a provider changes,
+// until ctx is done.
+func (cl *ConfigLoader) watchProviders(ctx context.Context, w *watcher) {
+	for _, p := range cl.providers {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			cl.refreshProvider(ctx, p)
+		}()
+	}
+}
+
+// refreshProvider reloads the configuration every time the provider changes,
+// or every poll interval if it can't be watched. When watching fails, it is
+// retried after the poll interval.
+func (cl *ConfigLoader) refreshProvider(ctx context.Context, p Provider) {
+	pw, watch := p.(ProviderWatcher)
+
+	for {
+		if watch {
+			err := pw.Watch(ctx)
+			switch {
+			case ctx.Err() != nil:
+				return
+			case errors.Is(err, ErrWatchNotSupported):
+				watch = false
+				continue
+			case err != nil:
+				cl.reportError(fmt.Errorf("failed to watch provider '%s': %w", p.Name(), err))
+				if !sleep(ctx, cl.pollInterval) {
+					return
+				}
+			}
+		} else if !sleep(ctx, cl.pollInterval) {
+			return
+		}
+
+		cl.reloadAndReport()
+	}
+}
+
+// sleep waits for d and reports whether ctx is still active.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
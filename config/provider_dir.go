@@ -0,0 +1,175 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DirDataLink is the symlink Kubernetes points to the current version of a
+// mounted ConfigMap or Secret, and swaps atomically on updates.
+const DirDataLink = "..data"
+
+// DirProvider reads configuration values from a directory holding one file
+// per key, such as a Kubernetes ConfigMap or Secret volume, or Docker
+// secrets. The lower-cased name of each file is the key and its content,
+// without trailing newlines, is the value, so "config.d/server-http-bind-addr"
+// sets "server-http-bind-addr". Hidden files are ignored.
+//
+// When the directory has a DirDataLink symlink, as Kubernetes volumes do,
+// the files are read from its target so a Read never mixes two versions.
+type DirProvider struct {
+	path   string
+	secret bool
+
+	mu      sync.Mutex
+	version string
+}
+
+// DirProviderOption configures a DirProvider.
+type DirProviderOption func(*DirProvider)
+
+// WithSecretDir marks every value of the directory as a secret, such as the
+// files of a Kubernetes Secret volume or Docker secrets, so they are redacted
+// like resolved references.
+func WithSecretDir() DirProviderOption {
+	return func(p *DirProvider) {
+		p.secret = true
+	}
+}
+
+// NewDirProvider returns a provider reading the files of the directory at path.
+func NewDirProvider(path string, options ...DirProviderOption) *DirProvider {
+	p := &DirProvider{path: path}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// Name returns the path of the directory.
+func (p *DirProvider) Name() string {
+	return p.path
+}
+
+// Secret reports whether the values of the directory are secrets.
+func (p *DirProvider) Secret() bool {
+	return p.secret
+}
+
+// Read returns the content of the files of the directory.
+func (p *DirProvider) Read(_ context.Context) (map[string]any, error) {
+	dir, version, err := p.current()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		values[strings.ToLower(entry.Name())] = strings.TrimRight(string(b), "\r\n")
+	}
+
+	p.mu.Lock()
+	p.version = version
+	p.mu.Unlock()
+
+	return values, nil
+}
+
+// Watch blocks until the files of the directory changed since the last Read,
+// e.g. when Kubernetes swaps the DirDataLink symlink.
+func (p *DirProvider) Watch(ctx context.Context) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = fsw.Close() }()
+
+	if err := fsw.Add(p.path); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-fsw.Events:
+			// wait for the events of an update to settle
+			timer.Reset(reloadDebounce)
+		case err := <-fsw.Errors:
+			return err
+		case <-timer.C:
+			_, version, err := p.current()
+			if err != nil {
+				return err
+			}
+
+			p.mu.Lock()
+			changed := version != p.version
+			p.mu.Unlock()
+
+			if changed {
+				return nil
+			}
+		}
+	}
+}
+
+// current returns the directory holding the current version of the files,
+// which is the target of DirDataLink when there is one, and a string
+// identifying that version.
+func (p *DirProvider) current() (string, string, error) {
+	target, err := filepath.EvalSymlinks(filepath.Join(p.path, DirDataLink))
+	if err == nil {
+		return target, target, nil
+	}
+
+	entries, err := os.ReadDir(p.path)
+	if err != nil {
+		return "", "", err
+	}
+
+	var version []string
+	for _, entry := range entries {
+		info, err := os.Stat(filepath.Join(p.path, entry.Name()))
+		if err != nil {
+			continue
+		}
+		version = append(version, fmt.Sprintf("%s:%d:%d", entry.Name(), info.Size(), info.ModTime().UnixNano()))
+	}
+	slices.Sort(version)
+
+	return p.path, strings.Join(version, ","), nil
+}
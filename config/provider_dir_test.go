@@ -0,0 +1,246 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// writeDataDir writes files to a new version of a Kubernetes style volume at
+// dir and atomically points its DirDataLink symlink to it.
+func writeDataDir(t *testing.T, dir, version string, files map[string]string) {
+	t.Helper()
+
+	data := filepath.Join(dir, "..data_"+version)
+	if err := os.Mkdir(data, 0o755); err != nil {
+		t.Fatalf("failed to create data directory: %v", err)
+	}
+	for name, content := range files {
+		writeConfigFile(t, data, name, content)
+
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); err == nil {
+			continue
+		}
+		if err := os.Symlink(filepath.Join(DirDataLink, name), link); err != nil {
+			t.Fatalf("failed to link %s: %v", name, err)
+		}
+	}
+
+	tmp := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(filepath.Base(data), tmp); err != nil {
+		t.Fatalf("failed to link data directory: %v", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, DirDataLink)); err != nil {
+		t.Fatalf("failed to swap data directory: %v", err)
+	}
+}
+
+func TestDirProvider_Read(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, dir string)
+		want  map[string]any
+	}{
+		{
+			name: "plain directory",
+			setup: func(t *testing.T, dir string) {
+				writeConfigFile(t, dir, "server-http-bind-addr", ":8080\n")
+				writeConfigFile(t, dir, "Log-Level", "DEBUG\r\n")
+				writeConfigFile(t, dir, ".hidden", "ignored")
+				if err := os.Mkdir(filepath.Join(dir, "nested"), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: map[string]any{"server-http-bind-addr": ":8080", "log-level": "DEBUG"},
+		},
+		{
+			name: "kubernetes volume",
+			setup: func(t *testing.T, dir string) {
+				writeDataDir(t, dir, "1", map[string]string{
+					"server-http-bind-addr": ":8080\n",
+					"session-secret":        "multi\nline\n",
+				})
+			},
+			want: map[string]any{"server-http-bind-addr": ":8080", "session-secret": "multi\nline"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.setup(t, dir)
+
+			p := NewDirProvider(dir)
+			if p.Name() != dir {
+				t.Errorf("Name() = %v, want %v", p.Name(), dir)
+			}
+
+			got, err := p.Read(context.Background())
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Read() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirProvider_Read_Missing(t *testing.T) {
+	p := NewDirProvider(filepath.Join(t.TempDir(), "missing"))
+	if _, err := p.Read(context.Background()); err == nil {
+		t.Error("Read() expected error for a missing directory")
+	}
+}
+
+func TestDirProvider_Watch(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T, dir string)
+		update func(t *testing.T, dir string)
+	}{
+		{
+			name: "plain directory",
+			setup: func(t *testing.T, dir string) {
+				writeConfigFile(t, dir, "port", "8080")
+			},
+			update: func(t *testing.T, dir string) {
+				writeConfigFile(t, dir, "port", "9090")
+			},
+		},
+		{
+			name: "kubernetes volume",
+			setup: func(t *testing.T, dir string) {
+				writeDataDir(t, dir, "1", map[string]string{"port": "8080"})
+			},
+			update: func(t *testing.T, dir string) {
+				writeDataDir(t, dir, "2", map[string]string{"port": "9090"})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.setup(t, dir)
+
+			p := NewDirProvider(dir)
+			if _, err := p.Read(context.Background()); err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				done <- p.Watch(ctx)
+			}()
+
+			select {
+			case err := <-done:
+				t.Fatalf("Watch() returned without a change: %v", err)
+			case <-time.After(2 * reloadDebounce):
+			}
+
+			tt.update(t, dir)
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatalf("Watch() error = %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Watch() didn't return after a change")
+			}
+		})
+	}
+}
+
+func TestConfigLoader_LoadConfig_SecretDir(t *testing.T) {
+	secrets := t.TempDir()
+	writeDataDir(t, secrets, "1", map[string]string{"dsn": "postgres://app:hunter2@db\n"})
+	unsetenv(t, "DSN", "PORT")
+
+	loader := NewConfigLoader(WithProviders(
+		NewDirProvider(secrets, WithSecretDir()),
+		&staticProvider{values: map[string]any{"port": 8080}},
+	))
+
+	var dsn string
+	_, err := loader.LoadConfig(
+		[]LoadOption{WithArgs(nil)},
+		func(fs *pflag.FlagSet) {
+			fs.StringVar(&dsn, "dsn", "", "Database DSN")
+			fs.Int("port", 80, "Port")
+		},
+	)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if dsn != "postgres://app:hunter2@db" {
+		t.Errorf("dsn = %v, want the value of the secret", dsn)
+	}
+	if !loader.IsSecret("dsn") || loader.IsSecret("port") {
+		t.Errorf("IsSecret() = %v, %v, want only dsn secret", loader.IsSecret("dsn"), loader.IsSecret("port"))
+	}
+
+	values := map[string]any{}
+	for _, e := range loader.Effective() {
+		values[e.Key] = e.Value
+	}
+	if values["dsn"] != Redacted {
+		t.Errorf("dsn = %v, want %v", values["dsn"], Redacted)
+	}
+	if values["port"] != int64(8080) {
+		t.Errorf("port = %v, want 8080", values["port"])
+	}
+}
+
+func TestConfigLoader_Watch_DirProvider(t *testing.T) {
+	dir := t.TempDir()
+	writeDataDir(t, dir, "1", map[string]string{"port": "8080\n"})
+	unsetenv(t, "PORT")
+
+	loader := NewConfigLoader(WithProviders(NewDirProvider(dir)))
+	t.Cleanup(func() {
+		_ = loader.Close()
+	})
+
+	changed := make(chan []Change, 10)
+	loader.Subscribe(func(changes []Change) {
+		changed <- changes
+	})
+
+	var port int
+	_, err := loader.LoadConfig(
+		[]LoadOption{WithWatch(true), WithArgs(nil)},
+		func(fs *pflag.FlagSet) {
+			fs.IntVar(&port, "port", 80, "Port")
+		},
+	)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if port != 8080 {
+		t.Fatalf("port = %v, want 8080", port)
+	}
+
+	writeDataDir(t, dir, "2", map[string]string{"port": "9090\n"})
+
+	select {
+	case changes := <-changed:
+		want := []Change{{Key: "port", Old: "8080", New: "9090"}}
+		if !reflect.DeepEqual(changes, want) {
+			t.Errorf("changes = %v, want %v", changes, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the configuration to reload")
+	}
+}
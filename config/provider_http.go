@@ -0,0 +1,203 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// httpRetryDelay is how long Watch waits before sending another request when
+// the endpoint answered that nothing changed without holding the request. It
+// doubles on every such answer, up to the long polling wait.
+const httpRetryDelay = time.Second
+
+// HTTPProvider reads configuration values from an HTTP endpoint returning a
+// JSON object, keyed like config files.
+type HTTPProvider struct {
+	url        string
+	name       string
+	client     *http.Client
+	header     http.Header
+	longPoll   time.Duration
+	retryDelay time.Duration
+
+	mu   sync.Mutex
+	etag string
+}
+
+// HTTPProviderOption configures an HTTPProvider.
+type HTTPProviderOption func(*HTTPProvider)
+
+// WithHTTPClient sets the client used to send requests. Defaults to
+// http.DefaultClient.
+func WithHTTPClient(client *http.Client) HTTPProviderOption {
+	return func(p *HTTPProvider) {
+		p.client = client
+	}
+}
+
+// WithHTTPHeader adds a header sent with every request, e.g. for
+// authentication.
+func WithHTTPHeader(key, value string) HTTPProviderOption {
+	return func(p *HTTPProvider) {
+		p.header.Add(key, value)
+	}
+}
+
+// WithLongPolling enables watching the endpoint with long polling: requests
+// are sent with the ETag of the last response in If-None-Match and
+// "Prefer: wait=<seconds>", and the endpoint is expected to hold them for up
+// to wait until the values change, answering 304 Not Modified otherwise.
+// Without it, the endpoint is polled.
+func WithLongPolling(wait time.Duration) HTTPProviderOption {
+	return func(p *HTTPProvider) {
+		p.longPoll = wait
+	}
+}
+
+// NewHTTPProvider returns a provider reading the JSON object at rawURL.
+func NewHTTPProvider(rawURL string, options ...HTTPProviderOption) *HTTPProvider {
+	p := &HTTPProvider{
+		url:        rawURL,
+		name:       redactURL(rawURL),
+		client:     http.DefaultClient,
+		header:     make(http.Header),
+		retryDelay: httpRetryDelay,
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	return p
+}
+
+// Name returns the URL of the endpoint, without its user information and
+// query, which may hold credentials.
+func (p *HTTPProvider) Name() string {
+	return p.name
+}
+
+// Read returns the values of the endpoint.
+func (p *HTTPProvider) Read(ctx context.Context) (map[string]any, error) {
+	resp, err := p.do(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var values map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&values); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	p.mu.Lock()
+	p.etag = resp.Header.Get("ETag")
+	p.mu.Unlock()
+
+	return values, nil
+}
+
+// Watch blocks until the endpoint answers that the values changed since the
+// last Read. It returns ErrWatchNotSupported unless long polling is enabled
+// and the endpoint returned an ETag. When the endpoint answers that nothing
+// changed without holding the request, the next one is delayed, starting at
+// one second and doubling up to the long polling wait, so an endpoint that
+// doesn't support long polling isn't sent requests in a loop. Errors are
+// returned, and retried by the ConfigLoader after its poll interval.
+func (p *HTTPProvider) Watch(ctx context.Context) error {
+	p.mu.Lock()
+	etag := p.etag
+	p.mu.Unlock()
+
+	if p.longPoll <= 0 || etag == "" {
+		return ErrWatchNotSupported
+	}
+
+	delay := p.retryDelay
+	for {
+		start := time.Now()
+		resp, err := p.do(ctx, http.Header{
+			"If-None-Match": {etag},
+			"Prefer":        {fmt.Sprintf("wait=%d", int(math.Ceil(p.longPoll.Seconds())))},
+		})
+		if err != nil {
+			return err
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != etag:
+			return nil
+		case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotModified:
+			return fmt.Errorf("unexpected status %s", resp.Status)
+		}
+
+		if time.Since(start) >= p.longPoll {
+			delay = p.retryDelay
+			continue
+		}
+
+		if !sleep(ctx, delay) {
+			return ctx.Err()
+		}
+		delay = min(2*delay, p.longPoll)
+	}
+}
+
+// do sends a GET request to the endpoint with the given headers. Errors name
+// the endpoint by its redacted URL.
+func (p *HTTPProvider) do(ctx context.Context, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, p.redactError(err)
+	}
+
+	req.Header = p.header.Clone()
+	req.Header.Set("Accept", "application/json")
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, p.redactError(err)
+	}
+	return resp, nil
+}
+
+// redactError replaces the URL of a *url.Error, which may hold credentials in
+// its user information or query, with the redacted one.
+func (p *HTTPProvider) redactError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = p.name
+	}
+	return err
+}
+
+// redactURL returns rawURL without its user information, query and fragment.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "invalid URL"
+	}
+
+	u.User = nil
+	u.RawQuery = ""
+	u.ForceQuery = false
+	u.Fragment = ""
+	return u.String()
+}
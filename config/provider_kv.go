@@ -0,0 +1,170 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// KVStore is a key/value store, such as Consul or etcd, holding configuration
+// values under a common prefix.
+type KVStore interface {
+	// List returns the value of every key starting with prefix, along with
+	// the index of the store when they were read, which increases with every
+	// change. Stores without such an index return 0.
+	List(ctx context.Context, prefix string) (map[string]string, uint64, error)
+}
+
+// KVWatcher is implemented by key/value stores that can notify changes.
+type KVWatcher interface {
+	// WatchPrefix blocks until a key starting with prefix changed after
+	// index, or ctx is done.
+	WatchPrefix(ctx context.Context, prefix string, index uint64) error
+}
+
+// KVProvider reads configuration values from the keys of a KVStore starting
+// with a prefix. The prefix is removed from the keys and the remaining "/"
+// separators are replaced by "-", so "myapp/server/bind-addr" sets
+// "server-bind-addr" with the prefix "myapp/".
+type KVProvider struct {
+	name   string
+	store  KVStore
+	prefix string
+
+	mu    sync.Mutex
+	index uint64
+}
+
+// NewKVProvider returns a provider named name reading the keys of store
+// starting with prefix.
+func NewKVProvider(name string, store KVStore, prefix string) *KVProvider {
+	return &KVProvider{
+		name:   name,
+		store:  store,
+		prefix: prefix,
+	}
+}
+
+// Name returns the name of the provider.
+func (p *KVProvider) Name() string {
+	return p.name
+}
+
+// Read returns the values of the keys starting with the prefix.
+func (p *KVProvider) Read(ctx context.Context) (map[string]any, error) {
+	pairs, index, err := p.store.List(ctx, p.prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any, len(pairs))
+	for key, value := range pairs {
+		key = strings.Trim(strings.TrimPrefix(key, p.prefix), "/")
+		if key == "" {
+			continue
+		}
+		values[strings.ReplaceAll(key, "/", "-")] = value
+	}
+
+	p.mu.Lock()
+	p.index = index
+	p.mu.Unlock()
+
+	return values, nil
+}
+
+// Watch blocks until a key starting with the prefix changed since the last
+// Read. It returns ErrWatchNotSupported if the store isn't a KVWatcher.
+func (p *KVProvider) Watch(ctx context.Context) error {
+	w, ok := p.store.(KVWatcher)
+	if !ok {
+		return ErrWatchNotSupported
+	}
+
+	p.mu.Lock()
+	index := p.index
+	p.mu.Unlock()
+
+	return w.WatchPrefix(ctx, p.prefix, index)
+}
+
+// MemoryStore is an in-memory KVStore and KVWatcher, to use a KVProvider
+// without an external store, e.g. in tests.
+type MemoryStore struct {
+	mu      sync.Mutex
+	values  map[string]string
+	changed map[string]uint64
+	index   uint64
+	notify  chan struct{}
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		values:  make(map[string]string),
+		changed: make(map[string]uint64),
+		notify:  make(chan struct{}),
+	}
+}
+
+// Put sets the value of key.
+func (s *MemoryStore) Put(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+	s.change(key)
+}
+
+// Delete removes key.
+func (s *MemoryStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.values[key]; !ok {
+		return
+	}
+	delete(s.values, key)
+	s.change(key)
+}
+
+// List returns the value of every key starting with prefix.
+func (s *MemoryStore) List(_ context.Context, prefix string) (map[string]string, uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := make(map[string]string)
+	for key, value := range s.values {
+		if strings.HasPrefix(key, prefix) {
+			values[key] = value
+		}
+	}
+	return values, s.index, nil
+}
+
+// WatchPrefix blocks until a key starting with prefix changed after index.
+func (s *MemoryStore) WatchPrefix(ctx context.Context, prefix string, index uint64) error {
+	for {
+		s.mu.Lock()
+		for key, changed := range s.changed {
+			if changed > index && strings.HasPrefix(key, prefix) {
+				s.mu.Unlock()
+				return nil
+			}
+		}
+		notify := s.notify
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-notify:
+		}
+	}
+}
+
+// change records a change of key and wakes up the watchers. s.mu must be held.
+func (s *MemoryStore) change(key string) {
+	s.index++
+	s.changed[key] = s.index
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// listStore is a KVStore that can't be watched.
+type listStore map[string]string
+
+func (s listStore) List(context.Context, string) (map[string]string, uint64, error) {
+	return s, 0, nil
+}
+
+func TestKVProvider_Read(t *testing.T) {
+	store := NewMemoryStore()
+	store.Put("myapp/port", "8080")
+	store.Put("myapp/server/bind-addr", ":8080")
+	store.Put("myapp/", "ignored")
+	store.Put("other/port", "9090")
+
+	p := NewKVProvider("consul", store, "myapp/")
+	if p.Name() != "consul" {
+		t.Errorf("Name() = %v, want consul", p.Name())
+	}
+
+	got, err := p.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	want := map[string]any{"port": "8080", "server-bind-addr": ":8080"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %v, want %v", got, want)
+	}
+}
+
+func TestKVProvider_Watch(t *testing.T) {
+	store := NewMemoryStore()
+	store.Put("myapp/port", "8080")
+
+	p := NewKVProvider("memory", store, "myapp/")
+	if _, err := p.Read(context.Background()); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- p.Watch(context.Background())
+	}()
+
+	store.Put("other/port", "9090")
+	store.Delete("myapp/missing")
+	select {
+	case err := <-done:
+		t.Fatalf("Watch() returned on a change outside the prefix: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	store.Delete("myapp/port")
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Watch() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch() didn't return after a change")
+	}
+
+	// a change made before watching is seen
+	store.Put("myapp/port", "8081")
+	if err := p.Watch(context.Background()); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.Read(context.Background()); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if err := p.Watch(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Watch() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestKVProvider_WatchNotSupported(t *testing.T) {
+	p := NewKVProvider("list", listStore{"port": "8080"}, "")
+
+	got, err := p.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if got["port"] != "8080" {
+		t.Errorf("port = %v, want 8080", got["port"])
+	}
+
+	if err := p.Watch(context.Background()); !errors.Is(err, ErrWatchNotSupported) {
+		t.Errorf("Watch() error = %v, want %v", err, ErrWatchNotSupported)
+	}
+}
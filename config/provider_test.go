@@ -0,0 +1,157 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// staticProvider is a provider that can't be watched.
+type staticProvider struct {
+	mu     sync.Mutex
+	values map[string]any
+	err    error
+}
+
+func (p *staticProvider) Name() string {
+	return "static"
+}
+
+func (p *staticProvider) Read(context.Context) (map[string]any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.values, p.err
+}
+
+func (p *staticProvider) set(values map[string]any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.values = values
+}
+
+func TestConfigLoader_LoadConfig_Providers(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "config.local.toml", `port = 1000
+name = "file"
+level = "file"
+`)
+
+	store := NewMemoryStore()
+	store.Put("myapp/port", "2000")
+	store.Put("myapp/name", "kv")
+	store.Put("myapp/level", "kv")
+
+	unsetenv(t, "PORT", "NAME", "LEVEL")
+	t.Setenv("LEVEL", "env")
+
+	loader := NewConfigLoader(
+		WithConfigPaths(dir),
+		WithProviders(
+			NewKVProvider("kv", store, "myapp/"),
+			&staticProvider{values: map[string]any{"port": 3000}},
+		),
+	)
+
+	var port int
+	var name, level string
+	_, err := loader.LoadConfig(
+		[]LoadOption{WithConfigFile(true), WithArgs(nil)},
+		func(fs *pflag.FlagSet) {
+			fs.IntVar(&port, "port", 80, "Port")
+			fs.StringVar(&name, "name", "default", "Name")
+			fs.StringVar(&level, "level", "default", "Level")
+		},
+	)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if port != 3000 {
+		t.Errorf("port = %v, want 3000 from the last provider", port)
+	}
+	if name != "kv" {
+		t.Errorf("name = %v, want kv", name)
+	}
+	if level != "env" {
+		t.Errorf("level = %v, want env", level)
+	}
+
+	want := map[string]Entry{
+		"port":  {Key: "port", Value: int64(3000), Source: SourceProvider, Origin: "static"},
+		"name":  {Key: "name", Value: "kv", Source: SourceProvider, Origin: "kv"},
+		"level": {Key: "level", Value: "env", Source: SourceEnv, Origin: "LEVEL"},
+	}
+	for _, e := range loader.Effective() {
+		if w, ok := want[e.Key]; ok && e != w {
+			t.Errorf("Effective() %s = %+v, want %+v", e.Key, e, w)
+		}
+	}
+}
+
+func TestConfigLoader_LoadConfig_ProviderError(t *testing.T) {
+	providerErr := errors.New("unavailable")
+	loader := NewConfigLoader(WithProviders(&staticProvider{err: providerErr}))
+
+	_, err := loader.LoadConfig([]LoadOption{WithArgs(nil)})
+	if !errors.Is(err, providerErr) {
+		t.Fatalf("LoadConfig() error = %v, want %v", err, providerErr)
+	}
+}
+
+func TestConfigLoader_Watch_Providers(t *testing.T) {
+	store := NewMemoryStore()
+	store.Put("myapp/level", "INFO")
+	static := &staticProvider{values: map[string]any{"name": "before"}}
+
+	loader := NewConfigLoader(
+		WithProviders(NewKVProvider("kv", store, "myapp/"), static),
+		WithPollInterval(10*time.Millisecond),
+	)
+	t.Cleanup(func() {
+		_ = loader.Close()
+	})
+
+	changed := make(chan []Change, 10)
+	loader.Subscribe(func(changes []Change) {
+		changed <- changes
+	})
+
+	var level, name string
+	_, err := loader.LoadConfig(
+		[]LoadOption{WithWatch(true), WithArgs(nil)},
+		func(fs *pflag.FlagSet) {
+			fs.StringVar(&level, "level", "default", "Level")
+			fs.StringVar(&name, "name", "default", "Name")
+		},
+	)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	waitForChange := func(key, want string) {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case changes := <-changed:
+				for _, c := range changes {
+					if c.Key == key && c.New == want {
+						return
+					}
+				}
+			case <-timeout:
+				t.Fatalf("timed out waiting for %s to change to %s", key, want)
+			}
+		}
+	}
+
+	store.Put("myapp/level", "DEBUG")
+	waitForChange("level", "DEBUG")
+
+	static.set(map[string]any{"name": "after"})
+	waitForChange("name", "after")
+}
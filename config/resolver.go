@@ -0,0 +1,180 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Redacted replaces the value of secrets wherever configuration values are shown.
+const Redacted = "[REDACTED]"
+
+// Resolver resolves a reference found in a configuration value, such as the
+// path of "file:///run/secrets/key", to the value it points to.
+type Resolver interface {
+	Resolve(ref string) (string, error)
+}
+
+// ResolverFunc is an adapter to allow the use of ordinary functions as Resolver.
+type ResolverFunc func(ref string) (string, error)
+
+// Resolve calls f(ref).
+func (f ResolverFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+// Prefixes of the references resolved by default.
+const (
+	FilePrefix   = "file://"
+	EnvPrefix    = "env://"
+	Base64Prefix = "base64:"
+)
+
+// defaultResolvers returns the resolvers registered on every ConfigLoader,
+// reading environment variables with lookupEnv.
+func defaultResolvers(lookupEnv func(name string) (string, bool)) map[string]Resolver {
+	return map[string]Resolver{
+		FilePrefix:   ResolverFunc(resolveFile),
+		EnvPrefix:    resolveEnv(lookupEnv),
+		Base64Prefix: ResolverFunc(resolveBase64),
+		EncryptedPrefix: ResolverFunc(func(string) (string, error) {
+			return "", ErrNoEncryptionKey
+		}),
+	}
+}
+
+// WithResolver registers a Resolver for values starting with prefix, such as
+// "vault://". The resolver is given the value without the prefix.
+// FilePrefix, EnvPrefix, Base64Prefix and EncryptedPrefix are registered by
+// default and can be replaced, or removed by passing a nil Resolver.
+// Resolved values are treated as secrets and are never shown.
+func WithResolver(prefix string, resolver Resolver) Option {
+	return func(cl *ConfigLoader) {
+		if resolver == nil {
+			delete(cl.resolvers, prefix)
+			return
+		}
+		cl.resolvers[prefix] = resolver
+	}
+}
+
+// IsSecret reports whether the value of key was resolved from a reference or
+// set by a SecretProvider.
+func (cl *ConfigLoader) IsSecret(key string) bool {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	return cl.secrets[key]
+}
+
+// resolve returns the value s references, and whether s was a reference.
+// The longest matching prefix wins.
+func (cl *ConfigLoader) resolve(s string) (string, bool, error) {
+	var prefix string
+	for p := range cl.resolvers {
+		if strings.HasPrefix(s, p) && len(p) > len(prefix) {
+			prefix = p
+		}
+	}
+
+	if prefix == "" {
+		return s, false, nil
+	}
+
+	value, err := cl.resolvers[prefix].Resolve(strings.TrimPrefix(s, prefix))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to resolve '%s' reference: %w", prefix, err)
+	}
+
+	return value, true, nil
+}
+
+// resolveValue resolves a value read by viper, or every element of a list.
+func (cl *ConfigLoader) resolveValue(value any) (any, bool, error) {
+	switch val := value.(type) {
+	case string:
+		return cl.resolve(val)
+	case []any:
+		resolved := make([]any, len(val))
+		secret := false
+		for i, elem := range val {
+			r, ok, err := cl.resolveValue(elem)
+			if err != nil {
+				return nil, false, err
+			}
+			resolved[i] = r
+			secret = secret || ok
+		}
+		return resolved, secret, nil
+	case []string:
+		resolved := make([]string, len(val))
+		secret := false
+		for i, elem := range val {
+			r, ok, err := cl.resolve(elem)
+			if err != nil {
+				return nil, false, err
+			}
+			resolved[i] = r
+			secret = secret || ok
+		}
+		return resolved, secret, nil
+	default:
+		return value, false, nil
+	}
+}
+
+// resolveFlagReferences resolves references given on the command line and
+// returns the keys of the flags that held one.
+func (cl *ConfigLoader) resolveFlagReferences(fs *pflag.FlagSet) (map[string]bool, error) {
+	secrets := make(map[string]bool)
+	var errs []error
+
+	fs.Visit(func(f *pflag.Flag) {
+		value, ok, err := cl.resolveValue(flagValue(f))
+		if err == nil && ok {
+			err = setFlagValue(f, value)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid value for '%s': %w", f.Name, err))
+			return
+		}
+		if ok {
+			secrets[f.Name] = true
+		}
+	})
+
+	return secrets, errors.Join(errs...)
+}
+
+// resolveFile returns the content of the file at path, without trailing newlines.
+func resolveFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// resolveEnv returns a resolver returning the value of the environment
+// variable name, looked up with lookupEnv.
+func resolveEnv(lookupEnv func(name string) (string, bool)) ResolverFunc {
+	return func(name string) (string, error) {
+		value, ok := lookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable '%s' is not set", name)
+		}
+		return value, nil
+	}
+}
+
+// resolveBase64 returns the decoded value of a base64 string.
+func resolveBase64(data string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestConfigLoader_Resolvers(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		os.Args = originalArgs
+	}()
+
+	dir := t.TempDir()
+	secretFile := writeConfigFile(t, dir, "session_key", "file-secret\n")
+
+	tests := []struct {
+		name       string
+		args       []string
+		file       string
+		envVars    map[string]string
+		options    []Option
+		want       string
+		wantSecret bool
+		errContain string
+	}{
+		{
+			name:       "file reference from config file",
+			file:       `secret = "file://` + filepath.ToSlash(secretFile) + `"`,
+			want:       "file-secret",
+			wantSecret: true,
+		},
+		{
+			name: "env reference from environment variable",
+			envVars: map[string]string{
+				"TEST_SECRET": "env://OTHER_VAR",
+				"OTHER_VAR":   "env-secret",
+			},
+			want:       "env-secret",
+			wantSecret: true,
+		},
+		{
+			name:       "base64 reference from flag",
+			args:       []string{"--secret", "base64:ZmxhZy1zZWNyZXQ="},
+			want:       "flag-secret",
+			wantSecret: true,
+		},
+		{
+			name: "custom resolver",
+			file: `secret = "vault://kv/app#key"`,
+			options: []Option{
+				WithResolver("vault://", ResolverFunc(func(ref string) (string, error) {
+					return "vault:" + ref, nil
+				})),
+			},
+			want:       "vault:kv/app#key",
+			wantSecret: true,
+		},
+		{
+			name:    "removed resolver",
+			file:    `secret = "base64:ZmxhZy1zZWNyZXQ="`,
+			options: []Option{WithResolver(Base64Prefix, nil)},
+			want:    "base64:ZmxhZy1zZWNyZXQ=",
+		},
+		{
+			name: "plain value",
+			file: `secret = "env:8080"`,
+			want: "env:8080",
+		},
+		{
+			name:       "unset environment variable",
+			file:       `secret = "env://MISSING_VAR"`,
+			errContain: "environment variable 'MISSING_VAR' is not set",
+		},
+		{
+			name:       "invalid base64",
+			file:       `secret = "base64:not base64"`,
+			errContain: "failed to resolve 'base64:' reference",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configDir := t.TempDir()
+			writeConfigFile(t, configDir, "config.test.toml", tt.file)
+
+			for key, value := range tt.envVars {
+				t.Setenv(key, value)
+			}
+
+			os.Args = append([]string{"testapp", "--env-name", "test"}, tt.args...)
+
+			var secret string
+			options := append([]Option{WithEnvPrefix("TEST"), WithConfigPaths(configDir)}, tt.options...)
+			loader := NewConfigLoader(options...)
+			_, err := loader.LoadConfig([]LoadOption{WithConfigFile(true)}, func(fs *pflag.FlagSet) {
+				fs.StringVar(&secret, "secret", "", "Secret")
+			})
+
+			if tt.errContain != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContain) {
+					t.Fatalf("LoadConfig() error = %v, want to contain %v", err, tt.errContain)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			if secret != tt.want {
+				t.Errorf("secret = %v, want %v", secret, tt.want)
+			}
+			if loader.IsSecret("secret") != tt.wantSecret {
+				t.Errorf("IsSecret() = %v, want %v", loader.IsSecret("secret"), tt.wantSecret)
+			}
+		})
+	}
+}
+
+func TestConfigLoader_Reload_RedactsSecrets(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		os.Args = originalArgs
+	}()
+
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "config.test.toml", `secret = "base64:b2xk"`)
+	os.Args = []string{"testapp", "--env-name", "test"}
+
+	var secret string
+	loader := NewConfigLoader(WithConfigPaths(dir))
+	_, err := loader.LoadConfig([]LoadOption{WithConfigFile(true)}, func(fs *pflag.FlagSet) {
+		fs.StringVar(&secret, "secret", "", "Secret")
+	})
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	var got []Change
+	loader.Subscribe(func(changes []Change) {
+		got = changes
+	})
+
+	writeConfigFile(t, dir, "config.test.toml", `secret = "base64:bmV3"`)
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if secret != "new" {
+		t.Errorf("secret = %v, want new", secret)
+	}
+	if len(got) != 1 || got[0].Old != Redacted || got[0].New != Redacted {
+		t.Errorf("changes = %v, want redacted values", got)
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// schemaKey is the key JSON config files use to reference their JSON Schema,
+// which is allowed in strict mode.
+const schemaKey = "$schema"
+
+// ErrUnknownKey is returned in strict mode when a config file, a provider or
+// an environment variable sets a key that isn't a flag.
+var ErrUnknownKey = errors.New("unknown configuration key")
+
+// WithStrict rejects config file and provider keys that aren't flags, and,
+// when an environment variable prefix is set, environment variables with the
+// prefix that don't set a flag. The error suggests the closest known key, to
+// catch typos such as "sever-http-bind-addr" that are ignored otherwise.
+func WithStrict(enabled bool) LoadOption {
+	return func(o *loadOptions) {
+		o.strict = enabled
+	}
+}
+
+// checkUnknownKeys returns an error for every key of files and environment
+// variable with the prefix of the loader that doesn't set a flag of fs.
+func (cl *ConfigLoader) checkUnknownKeys(fs *pflag.FlagSet, files []configFile) error {
+	keys := make(map[string]bool)
+	envs := make(map[string]bool)
+	fs.VisitAll(func(f *pflag.Flag) {
+		keys[f.Name] = true
+		for _, alias := range aliasesOf(f) {
+			keys[alias.Name] = true
+		}
+		for _, env := range append(cl.envNames(f), cl.aliasEnvs(f)...) {
+			envs[env] = true
+		}
+	})
+
+	var errs []error
+	for _, file := range files {
+		for _, key := range slices.Sorted(maps.Keys(file.values)) {
+			if keys[key] || key == schemaKey {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("%w '%s' in %s %s%s",
+				ErrUnknownKey, key, file.source, file.path, suggest(key, keys)))
+		}
+	}
+
+	if cl.envVarPrefix != "" {
+		prefix := strings.ToUpper(cl.envVarPrefix) + "_"
+		var unknown []string
+		names := slices.Collect(maps.Keys(cl.dotenvVars()))
+		for _, env := range os.Environ() {
+			name, _, _ := strings.Cut(env, "=")
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range slices.Compact(names) {
+			if strings.HasPrefix(name, prefix) && !envs[name] {
+				unknown = append(unknown, name)
+			}
+		}
+
+		for _, name := range unknown {
+			errs = append(errs, fmt.Errorf("%w '%s' in %s%s",
+				ErrUnknownKey, name, SourceEnv, suggest(name, envs)))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// suggest returns ", did you mean '<key>'?" with the known key closest to
+// name, or an empty string if none is close enough to be a typo.
+func suggest(name string, known map[string]bool) string {
+	best, bestDistance := "", max(2, len(name)/4)+1
+	for _, key := range slices.Sorted(maps.Keys(known)) {
+		if d := editDistance(name, key); d < bestDistance {
+			best, bestDistance = key, d
+		}
+	}
+
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean '%s'?", best)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
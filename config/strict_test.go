@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestConfigLoader_LoadConfig_Strict(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		env      map[string]string
+		provider map[string]any
+		strict   bool
+		wantErrs []string
+	}{
+		{
+			name:   "known keys",
+			file:   "server-http-bind-addr = \":8080\"\nold-port = 80\n\"$schema\" = \"schema.json\"\n",
+			env:    map[string]string{"MYAPP_LOG_LEVEL": "DEBUG", "MYAPP_OLD_PORT": "80"},
+			strict: true,
+		},
+		{
+			name: "not strict",
+			file: "sever-http-bind-addr = \":8080\"\n",
+			env:  map[string]string{"MYAPP_LOG_LEVL": "DEBUG"},
+		},
+		{
+			name:   "unknown file key",
+			file:   "sever-http-bind-addr = \":8080\"\n",
+			strict: true,
+			wantErrs: []string{
+				"unknown configuration key 'sever-http-bind-addr' in file <file>, did you mean 'server-http-bind-addr'?",
+			},
+		},
+		{
+			name:     "unknown provider key",
+			provider: map[string]any{"log-levle": "DEBUG"},
+			strict:   true,
+			wantErrs: []string{"'log-levle' in provider static, did you mean 'log-level'?"},
+		},
+		{
+			name:     "unknown env",
+			env:      map[string]string{"MYAPP_LOG_LEVL": "DEBUG"},
+			strict:   true,
+			wantErrs: []string{"'MYAPP_LOG_LEVL' in env, did you mean 'MYAPP_LOG_LEVEL'?"},
+		},
+		{
+			name:     "no suggestion",
+			file:     "completely-unrelated = true\n",
+			strict:   true,
+			wantErrs: []string{"'completely-unrelated' in file <file>\n"},
+		},
+		{
+			name:   "every unknown key",
+			file:   "sever-http-bind-addr = \":8080\"\nprot = 80\n",
+			env:    map[string]string{"MYAPP_PROT": "80"},
+			strict: true,
+			wantErrs: []string{
+				"'prot' in file <file>, did you mean 'port'?",
+				"'sever-http-bind-addr' in file <file>, did you mean 'server-http-bind-addr'?",
+				"'MYAPP_PROT' in env, did you mean 'MYAPP_PORT'?",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.file != "" {
+				writeConfigFile(t, dir, "config.local.toml", tt.file)
+			}
+
+			unsetenv(t, "MYAPP_LOG_LEVEL", "MYAPP_LOG_LEVL", "MYAPP_OLD_PORT", "MYAPP_PROT")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			options := []Option{WithEnvPrefix("MYAPP"), WithConfigPaths(dir)}
+			if tt.provider != nil {
+				options = append(options, WithProviders(&staticProvider{values: tt.provider}))
+			}
+			loader := NewConfigLoader(options...)
+
+			var port int
+			_, err := loader.LoadConfig(
+				[]LoadOption{WithArgs(nil), WithConfigFile(tt.file != ""), WithStrict(tt.strict)},
+				aliasFlags(t, &port),
+				func(fs *pflag.FlagSet) {
+					fs.String("server-http-bind-addr", ":1323", "Bind address")
+					fs.String("log-level", "INFO", "Log level")
+				},
+			)
+
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("LoadConfig() error = %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrUnknownKey) {
+				t.Fatalf("LoadConfig() error = %v, want %v", err, ErrUnknownKey)
+			}
+			msg := err.Error() + "\n"
+			for _, want := range tt.wantErrs {
+				want = strings.ReplaceAll(want, "<file>", filepath.Join(dir, "config.local.toml"))
+				if !strings.Contains(msg, want) {
+					t.Errorf("LoadConfig() error = %v, want it to contain %q", err, want)
+				}
+			}
+			if n := strings.Count(msg, ErrUnknownKey.Error()); n != len(tt.wantErrs) {
+				t.Errorf("LoadConfig() reported %d unknown keys, want %d", n, len(tt.wantErrs))
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"port", "", 4},
+		{"port", "port", 0},
+		{"prot", "port", 2},
+		{"sever", "server", 1},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := editDistance(tt.a, tt.b); got != tt.want {
+				t.Errorf("editDistance(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
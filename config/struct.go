@@ -0,0 +1,250 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/spf13/pflag"
+)
+
+// Struct tags understood by StructFlagSet and LoadInto.
+const (
+	// TagName sets the key of a field, used as flag name and config file key.
+	// Nested struct keys are prefixed with their parent key, separated by "-".
+	// Use "-" to skip a field. Defaults to the kebab-case field name.
+	TagName = "config"
+
+	// TagDefault sets the default value of a field when its value is zero.
+	TagDefault = "default"
+
+	// TagUsage sets the help text of the flag.
+	TagUsage = "usage"
+
+	// TagEnv overrides the environment variable name of a field.
+	// The environment variable prefix is not applied to it.
+	TagEnv = "env"
+)
+
+// envAnnotation is the flag annotation holding explicit environment variable names.
+const envAnnotation = "config_env"
+
+var valueType = reflect.TypeOf((*pflag.Value)(nil)).Elem()
+
+// StructFlagSet returns a pflag.FlagSet whose flags are bound to the fields
+// of target, which must be a pointer to a struct. Flag names are derived from
+// the `config` struct tag or the field name, nested structs are prefixed with
+// their parent's key, and embedded structs are flattened.
+func StructFlagSet(name string, target any) (*pflag.FlagSet, error) {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("target must be a non-nil pointer to a struct, got %T", target)
+	}
+
+	fs := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	if err := bindStruct(fs, rv.Elem(), ""); err != nil {
+		return nil, err
+	}
+
+	return fs, nil
+}
+
+// LoadInto loads configuration into target, a pointer to a struct, using the
+// same sources and precedence as LoadConfig, and validates it against its
+// `validate` struct tags. See StructFlagSet for how flag names, environment
+// variables and config file keys are derived.
+func (cl *ConfigLoader) LoadInto(target any, options ...LoadOption) (*Config, error) {
+	structFlags, err := StructFlagSet("config", target)
+	if err != nil {
+		return nil, err
+	}
+
+	options = append(options, WithValidators(func() error {
+		return ValidateStruct(target, structFlags)
+	}))
+
+	return cl.LoadConfig(options, func(fs *pflag.FlagSet) {
+		fs.AddFlagSet(structFlags)
+	})
+}
+
+// bindStruct adds a flag for every supported field of v.
+func bindStruct(fs *pflag.FlagSet, v reflect.Value, prefix string) error {
+	t := v.Type()
+	var errs []error
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() && !(field.Anonymous && field.Type.Kind() == reflect.Struct) {
+			continue
+		}
+
+		tag := field.Tag.Get(TagName)
+		if tag == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+		nested := isNestedStruct(fv)
+		name := tag
+		if name == "" && !(field.Anonymous && nested) {
+			name = toKebabCase(field.Name)
+		}
+
+		key := name
+		if prefix != "" && name != "" {
+			key = prefix + "-" + name
+		} else if name == "" {
+			key = prefix
+		}
+
+		if nested {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if err := bindStruct(fs, fv, key); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		if fs.Lookup(key) != nil {
+			errs = append(errs, fmt.Errorf("field '%s': duplicate key '%s'", field.Name, key))
+			continue
+		}
+
+		usage := field.Tag.Get(TagUsage)
+		if def, ok := field.Tag.Lookup(TagDefault); ok && fv.IsZero() {
+			tmp := pflag.NewFlagSet("default", pflag.ContinueOnError)
+			if err := bindField(tmp, fv, key, usage); err != nil {
+				errs = append(errs, fmt.Errorf("field '%s': %w", field.Name, err))
+				continue
+			}
+			if err := tmp.Set(key, def); err != nil {
+				errs = append(errs, fmt.Errorf("field '%s': invalid default '%s': %w", field.Name, def, err))
+				continue
+			}
+		}
+
+		if err := bindField(fs, fv, key, usage); err != nil {
+			errs = append(errs, fmt.Errorf("field '%s': %w", field.Name, err))
+			continue
+		}
+
+		if env := field.Tag.Get(TagEnv); env != "" {
+			_ = fs.SetAnnotation(key, envAnnotation, []string{env})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// isNestedStruct reports whether v is a struct, or a pointer to one, that
+// should be walked rather than bound to a single flag.
+func isNestedStruct(v reflect.Value) bool {
+	t := v.Type()
+	if t.Implements(valueType) || reflect.PointerTo(t).Implements(valueType) {
+		return false
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// bindField adds a flag bound to the field v.
+func bindField(fs *pflag.FlagSet, v reflect.Value, name, usage string) error {
+	ptr := v.Addr().Interface()
+
+	if value, ok := ptr.(pflag.Value); ok {
+		fs.Var(value, name, usage)
+		return nil
+	}
+
+	switch p := ptr.(type) {
+	case *time.Duration:
+		fs.DurationVar(p, name, *p, usage)
+	case *string:
+		fs.StringVar(p, name, *p, usage)
+	case *bool:
+		fs.BoolVar(p, name, *p, usage)
+	case *int:
+		fs.IntVar(p, name, *p, usage)
+	case *int8:
+		fs.Int8Var(p, name, *p, usage)
+	case *int16:
+		fs.Int16Var(p, name, *p, usage)
+	case *int32:
+		fs.Int32Var(p, name, *p, usage)
+	case *int64:
+		fs.Int64Var(p, name, *p, usage)
+	case *uint:
+		fs.UintVar(p, name, *p, usage)
+	case *uint8:
+		fs.Uint8Var(p, name, *p, usage)
+	case *uint16:
+		fs.Uint16Var(p, name, *p, usage)
+	case *uint32:
+		fs.Uint32Var(p, name, *p, usage)
+	case *uint64:
+		fs.Uint64Var(p, name, *p, usage)
+	case *float32:
+		fs.Float32Var(p, name, *p, usage)
+	case *float64:
+		fs.Float64Var(p, name, *p, usage)
+	case *[]string:
+		fs.StringSliceVar(p, name, *p, usage)
+	case *[]int:
+		fs.IntSliceVar(p, name, *p, usage)
+	case *[]int64:
+		fs.Int64SliceVar(p, name, *p, usage)
+	case *[]uint:
+		fs.UintSliceVar(p, name, *p, usage)
+	case *[]bool:
+		fs.BoolSliceVar(p, name, *p, usage)
+	case *[]float64:
+		fs.Float64SliceVar(p, name, *p, usage)
+	case *[]time.Duration:
+		fs.DurationSliceVar(p, name, *p, usage)
+	case *map[string]string:
+		fs.StringToStringVar(p, name, *p, usage)
+	case *map[string]int:
+		fs.StringToIntVar(p, name, *p, usage)
+	case *map[string]int64:
+		fs.StringToInt64Var(p, name, *p, usage)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// toKebabCase converts a Go field name to kebab-case, keeping acronyms
+// together, e.g. "HTTPBindAddr" becomes "http-bind-addr".
+func toKebabCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				prev := runes[i-1]
+				nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+					b.WriteByte('-')
+				}
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
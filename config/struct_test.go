@@ -0,0 +1,197 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testHTTPConfig struct {
+	BindAddr    string        `default:"localhost:8080" usage:"HTTP bind address"`
+	ReadTimeout time.Duration `default:"10s"`
+	MaxBody     ByteSize      `config:"max-body" default:"1MiB"`
+}
+
+type testEmbedded struct {
+	Debug bool `usage:"Enable debug mode"`
+}
+
+type testAppConfig struct {
+	testEmbedded
+	HTTP     testHTTPConfig
+	Origins  []string          `default:"*"`
+	Ports    []int             `config:"ports"`
+	Labels   map[string]string `config:"labels"`
+	Token    string            `env:"CUSTOM_TOKEN"`
+	Internal string            `config:"-"`
+	private  string
+}
+
+func TestStructFlagSet(t *testing.T) {
+	cfg := &testAppConfig{}
+	fs, err := StructFlagSet("test", cfg)
+	if err != nil {
+		t.Fatalf("StructFlagSet() error = %v", err)
+	}
+
+	for _, name := range []string{
+		"debug", "http-bind-addr", "http-read-timeout", "http-max-body",
+		"origins", "ports", "labels", "token",
+	} {
+		if fs.Lookup(name) == nil {
+			t.Errorf("expected flag '%s' to be defined", name)
+		}
+	}
+
+	for _, name := range []string{"internal", "private"} {
+		if fs.Lookup(name) != nil {
+			t.Errorf("expected flag '%s' to be skipped", name)
+		}
+	}
+
+	if cfg.HTTP.BindAddr != "localhost:8080" {
+		t.Errorf("HTTP.BindAddr = %v, want localhost:8080", cfg.HTTP.BindAddr)
+	}
+	if cfg.HTTP.ReadTimeout != 10*time.Second {
+		t.Errorf("HTTP.ReadTimeout = %v, want 10s", cfg.HTTP.ReadTimeout)
+	}
+	if cfg.HTTP.MaxBody != MiB {
+		t.Errorf("HTTP.MaxBody = %v, want %v", cfg.HTTP.MaxBody, MiB)
+	}
+	if len(cfg.Origins) != 1 || cfg.Origins[0] != "*" {
+		t.Errorf("Origins = %v, want [*]", cfg.Origins)
+	}
+	if usage := fs.Lookup("http-bind-addr").Usage; usage != "HTTP bind address" {
+		t.Errorf("usage = %v, want HTTP bind address", usage)
+	}
+	if got := fs.Lookup("token").Annotations[envAnnotation]; len(got) != 1 || got[0] != "CUSTOM_TOKEN" {
+		t.Errorf("env annotation = %v, want [CUSTOM_TOKEN]", got)
+	}
+}
+
+func TestStructFlagSet_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     any
+		errContain string
+	}{
+		{
+			name:       "not a pointer",
+			target:     testAppConfig{},
+			errContain: "non-nil pointer to a struct",
+		},
+		{
+			name:       "unsupported type",
+			target:     &struct{ Ch chan int }{},
+			errContain: "unsupported type chan int",
+		},
+		{
+			name: "invalid default",
+			target: &struct {
+				Port int `default:"abc"`
+			}{},
+			errContain: "invalid default 'abc'",
+		},
+		{
+			name: "duplicate key",
+			target: &struct {
+				A string `config:"name"`
+				B string `config:"name"`
+			}{},
+			errContain: "duplicate key 'name'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := StructFlagSet("test", tt.target)
+			if err == nil || !strings.Contains(err.Error(), tt.errContain) {
+				t.Errorf("StructFlagSet() error = %v, want to contain %v", err, tt.errContain)
+			}
+		})
+	}
+}
+
+func TestConfigLoader_LoadInto(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		os.Args = originalArgs
+	}()
+
+	dir := t.TempDir()
+	content := `http-bind-addr = "0.0.0.0:80"
+http-max-body = "10MB"
+ports = [80, 443]
+origins = ["https://example.com"]
+
+[labels]
+team = "core"
+`
+	if err := os.WriteFile(filepath.Join(dir, "config.test.toml"), []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	t.Setenv("TEST_HTTP_READ_TIMEOUT", "30s")
+	t.Setenv("CUSTOM_TOKEN", "s3cr3t")
+	os.Args = []string{"testapp", "--env-name", "test", "--debug"}
+
+	cfg := &testAppConfig{}
+	loader := NewConfigLoader(WithEnvPrefix("TEST"), WithConfigPaths(dir))
+	c, err := loader.LoadInto(cfg, WithConfigFile(true))
+	if err != nil {
+		t.Fatalf("LoadInto() error = %v", err)
+	}
+
+	if c.EnvName != "test" {
+		t.Errorf("EnvName = %v, want test", c.EnvName)
+	}
+	if !cfg.Debug {
+		t.Errorf("Debug = %v, want true", cfg.Debug)
+	}
+	if cfg.HTTP.BindAddr != "0.0.0.0:80" {
+		t.Errorf("HTTP.BindAddr = %v, want 0.0.0.0:80", cfg.HTTP.BindAddr)
+	}
+	if cfg.HTTP.ReadTimeout != 30*time.Second {
+		t.Errorf("HTTP.ReadTimeout = %v, want 30s", cfg.HTTP.ReadTimeout)
+	}
+	if cfg.HTTP.MaxBody != 10*MB {
+		t.Errorf("HTTP.MaxBody = %v, want %v", cfg.HTTP.MaxBody, 10*MB)
+	}
+	if len(cfg.Ports) != 2 || cfg.Ports[1] != 443 {
+		t.Errorf("Ports = %v, want [80 443]", cfg.Ports)
+	}
+	if len(cfg.Origins) != 1 || cfg.Origins[0] != "https://example.com" {
+		t.Errorf("Origins = %v, want [https://example.com]", cfg.Origins)
+	}
+	if cfg.Labels["team"] != "core" {
+		t.Errorf("Labels = %v, want team=core", cfg.Labels)
+	}
+	if cfg.Token != "s3cr3t" {
+		t.Errorf("Token = %v, want s3cr3t", cfg.Token)
+	}
+}
+
+func TestToKebabCase(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Name", "name"},
+		{"BindAddr", "bind-addr"},
+		{"HTTP", "http"},
+		{"HTTPBindAddr", "http-bind-addr"},
+		{"ACMEHostWhitelist", "acme-host-whitelist"},
+		{"MaxHeaderBytes", "max-header-bytes"},
+		{"Level2Cache", "level2-cache"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := toKebabCase(tt.input); got != tt.expected {
+				t.Errorf("toKebabCase(%s) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
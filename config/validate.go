@@ -0,0 +1,468 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// TagValidate lists the validation rules of a field, separated by ",".
+// Rules with a parameter use "rule=param", e.g. `validate:"required,min=1"`.
+//
+// Built-in rules:
+//
+//	required     the value must not be empty
+//	min=N        numbers and durations must be at least N, strings, slices
+//	             and maps must have a length of at least N
+//	max=N        same as min, as an upper bound
+//	gt=N, lt=N   same as min and max, excluding N
+//	oneof=A B C  the value must be one of the space separated values,
+//	             compared case-insensitively
+//	ne=A         the value must not be A, compared case-insensitively
+//	url          the value must be an absolute URL
+//	hostport     the value must be a "host:port" address
+//	file         the value must be the path of an existing file
+//	dir          the value must be the path of an existing directory
+//	cidr         the value must be a CIDR notation IP address and prefix
+//
+// Rules other than required, min, max, gt and lt apply to every element of a slice
+// and are skipped for empty values, so optional fields only need to be valid
+// when set.
+const TagValidate = "validate"
+
+// Rule checks a single value against the rule's parameter. The returned error
+// should complete a sentence starting with the field name, e.g.
+// "must be a valid email address".
+type Rule func(value any, param string) error
+
+// FieldError describes a field that violates a validation rule.
+type FieldError struct {
+	// Key is the name of the flag bound to the field, or the key derived from
+	// the field name when no flag is bound to it.
+	Key string
+
+	// Field is the path of the field in the struct, e.g. "HTTP.BindAddr".
+	Field string
+
+	// Rule is the violated rule, e.g. "min=1".
+	Rule string
+
+	// Err describes the violation.
+	Err error
+}
+
+// Error returns the violation prefixed with the field's key.
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("'%s' %v", e.Key, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError holds every violation found by ValidateStruct.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+// Error returns all violations, one per line.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the violations so they can be inspected with errors.As.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// fieldRules are checked against the field itself rather than its elements.
+var fieldRules = map[string]func(v reflect.Value, param string) error{
+	"required": ruleRequired,
+	"min":      ruleMin,
+	"max":      ruleMax,
+	"gt":       ruleGreaterThan,
+	"lt":       ruleLessThan,
+}
+
+var (
+	rulesMu sync.RWMutex
+	rules   = map[string]Rule{
+		"oneof":    ruleOneOf,
+		"ne":       ruleNotEqual,
+		"url":      ruleURL,
+		"hostport": ruleHostPort,
+		"file":     ruleFile,
+		"dir":      ruleDir,
+		"cidr":     ruleCIDR,
+	}
+)
+
+// RegisterRule adds a rule usable in the `validate` struct tags of every
+// validation. Registering a rule with the name of an existing one replaces it.
+// Use WithRule for a rule only some validations use.
+func RegisterRule(name string, rule Rule) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[name] = rule
+}
+
+// ValidateOption configures ValidateStruct.
+type ValidateOption func(*validateOptions)
+
+type validateOptions struct {
+	rules map[string]Rule
+}
+
+// WithRule adds a rule usable in the `validate` struct tags of a single
+// validation, taking precedence over a registered rule of the same name.
+func WithRule(name string, rule Rule) ValidateOption {
+	return func(o *validateOptions) {
+		o.rules[name] = rule
+	}
+}
+
+// ValidateStruct checks the fields of target, a pointer to a struct, against
+// their `validate` struct tags and returns a *ValidationError listing every
+// violation. Violations are reported with the name of the flag of fs bound to
+// the field, so fs should be the FlagSet built from target. When fs is nil or
+// has no flag for a field, the key is derived like StructFlagSet does.
+// Use ValidateComponent for a component, whose FlagSet rebinds its fields.
+func ValidateStruct(target any, fs *pflag.FlagSet, options ...ValidateOption) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("target must be a non-nil pointer to a struct, got %T", target)
+	}
+
+	o := &validateOptions{rules: map[string]Rule{}}
+	for _, opt := range options {
+		opt(o)
+	}
+
+	sv := &structValidator{names: flagNames(fs), seen: map[uintptr]bool{rv.Pointer(): true}, rules: o.rules}
+	sv.validate(rv.Elem(), "", "")
+	if len(sv.errs) > 0 {
+		return &ValidationError{Errors: sv.errs}
+	}
+
+	return nil
+}
+
+// ValidateComponent checks c, a pointer to a struct, like ValidateStruct,
+// naming violations after the flags of its FlagSet. The flag set is built
+// from a copy of c, so c isn't written by binding its flags and may be read
+// concurrently, e.g. by requests while the configuration is reloaded.
+func ValidateComponent(c Configurable, options ...ValidateOption) error {
+	cp := detach(c)
+	return ValidateStruct(cp, cp.FlagSet(), options...)
+}
+
+// structValidator collects the violations of a struct and its nested structs.
+type structValidator struct {
+	names map[uintptr]string
+	seen  map[uintptr]bool
+	rules map[string]Rule
+	errs  []*FieldError
+}
+
+// validate checks the fields of v, whose path and key prefix are given.
+func (sv *structValidator) validate(v reflect.Value, path, prefix string) {
+	t := v.Type()
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fv := v.Field(i)
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+
+		name := field.Tag.Get(TagName)
+		if name == "-" {
+			name = ""
+		} else if name == "" && !field.Anonymous {
+			name = toKebabCase(field.Name)
+		}
+		key := name
+		if prefix != "" && name != "" {
+			key = prefix + "-" + name
+		} else if name == "" {
+			key = prefix
+		}
+
+		if fv.Kind() == reflect.Func {
+			continue
+		}
+
+		if tag := field.Tag.Get(TagValidate); tag != "" {
+			if flag, ok := sv.names[fv.Addr().Pointer()]; ok {
+				key = flag
+			}
+			for _, rule := range strings.Split(tag, ",") {
+				if err := checkRule(fv, strings.TrimSpace(rule), sv.rules); err != nil {
+					sv.errs = append(sv.errs, &FieldError{Key: key, Field: fieldPath, Rule: rule, Err: err})
+				}
+			}
+		}
+
+		if isNestedStruct(fv) {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() || sv.seen[fv.Pointer()] {
+					continue
+				}
+				sv.seen[fv.Pointer()] = true
+				fv = fv.Elem()
+			}
+			sv.validate(fv, fieldPath, key)
+		}
+	}
+}
+
+// checkRule checks v against a single rule of a `validate` tag, looked up in
+// local before the registered rules.
+func checkRule(v reflect.Value, rule string, local map[string]Rule) error {
+	name, param, _ := strings.Cut(rule, "=")
+
+	if check, ok := fieldRules[name]; ok {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if name == "required" {
+					return errors.New("is required")
+				}
+				return nil
+			}
+			v = v.Elem()
+		}
+		return check(v, param)
+	}
+
+	check, ok := local[name]
+	if !ok {
+		rulesMu.RLock()
+		check, ok = rules[name]
+		rulesMu.RUnlock()
+	}
+	if !ok {
+		return fmt.Errorf("has unknown validation rule '%s'", name)
+	}
+
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		for i := range v.Len() {
+			if err := checkValue(v.Index(i), check, param); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return checkValue(v, check, param)
+}
+
+// checkValue runs check against v unless v is empty.
+func checkValue(v reflect.Value, check Rule, param string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.IsZero() {
+		return nil
+	}
+
+	if v.CanAddr() {
+		if value, ok := v.Addr().Interface().(pflag.Value); ok {
+			return check(value.String(), param)
+		}
+	}
+
+	return check(v.Interface(), param)
+}
+
+// flagNames maps the address of every variable bound to a flag of fs to the
+// flag's name.
+func flagNames(fs *pflag.FlagSet) map[uintptr]string {
+	names := map[uintptr]string{}
+	if fs == nil {
+		return names
+	}
+
+	fs.VisitAll(func(f *pflag.Flag) {
+		v := reflect.ValueOf(f.Value)
+		if v.Kind() != reflect.Pointer || v.IsNil() {
+			return
+		}
+		names[v.Pointer()] = f.Name
+
+		// Slice and map values wrap a pointer to the bound variable.
+		if e := v.Elem(); e.Kind() == reflect.Struct && e.NumField() > 0 && e.Field(0).Kind() == reflect.Pointer {
+			names[e.Field(0).Pointer()] = f.Name
+		}
+	})
+
+	return names
+}
+
+func ruleRequired(v reflect.Value, _ string) error {
+	switch v.Kind() {
+	case reflect.String:
+		if strings.TrimSpace(v.String()) == "" {
+			return errors.New("is required")
+		}
+	case reflect.Slice, reflect.Map:
+		if v.Len() == 0 {
+			return errors.New("is required")
+		}
+	default:
+		if v.IsZero() {
+			return errors.New("is required")
+		}
+	}
+	return nil
+}
+
+func ruleMin(v reflect.Value, param string) error {
+	return checkBound(v, "min", param, func(n, bound float64) bool { return n >= bound }, "at least")
+}
+
+func ruleMax(v reflect.Value, param string) error {
+	return checkBound(v, "max", param, func(n, bound float64) bool { return n <= bound }, "at most")
+}
+
+func ruleGreaterThan(v reflect.Value, param string) error {
+	return checkBound(v, "gt", param, func(n, bound float64) bool { return n > bound }, "greater than")
+}
+
+func ruleLessThan(v reflect.Value, param string) error {
+	return checkBound(v, "lt", param, func(n, bound float64) bool { return n < bound }, "less than")
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// checkBound compares the number, duration or length of v with param.
+func checkBound(v reflect.Value, rule, param string, ok func(n, bound float64) bool, desc string) error {
+	if v.Type() == durationType {
+		bound, err := time.ParseDuration(param)
+		if err != nil {
+			return fmt.Errorf("has invalid rule '%s=%s': %w", rule, param, err)
+		}
+		if d := time.Duration(v.Int()); !ok(float64(d), float64(bound)) {
+			return fmt.Errorf("must be %s %s, got %s", desc, bound, d)
+		}
+		return nil
+	}
+
+	bound, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return fmt.Errorf("has invalid rule '%s=%s': %w", rule, param, err)
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !ok(float64(v.Int()), bound) {
+			return fmt.Errorf("must be %s %s, got %d", desc, param, v.Int())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !ok(float64(v.Uint()), bound) {
+			return fmt.Errorf("must be %s %s, got %d", desc, param, v.Uint())
+		}
+	case reflect.Float32, reflect.Float64:
+		if !ok(v.Float(), bound) {
+			return fmt.Errorf("must be %s %s, got %g", desc, param, v.Float())
+		}
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		if !ok(float64(v.Len()), bound) {
+			return fmt.Errorf("must have a length of %s %s, got %d", desc, param, v.Len())
+		}
+	default:
+		return fmt.Errorf("has rule '%s' unsupported for type %s", rule, v.Type())
+	}
+
+	return nil
+}
+
+func ruleOneOf(value any, param string) error {
+	s := fmt.Sprint(value)
+	values := strings.Fields(param)
+	for _, v := range values {
+		if strings.EqualFold(s, v) {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of: %s, got '%s'", strings.Join(values, ", "), s)
+}
+
+func ruleNotEqual(value any, param string) error {
+	if s := fmt.Sprint(value); strings.EqualFold(s, param) {
+		return fmt.Errorf("must not be '%s'", s)
+	}
+	return nil
+}
+
+func ruleURL(value any, _ string) error {
+	s := fmt.Sprint(value)
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("must be an absolute URL, got '%s'", s)
+	}
+	return nil
+}
+
+func ruleHostPort(value any, _ string) error {
+	s := fmt.Sprint(value)
+	_, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return fmt.Errorf("must be a host:port address, got '%s'", s)
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return fmt.Errorf("must have a port between 0 and 65535, got '%s'", s)
+	}
+	return nil
+}
+
+func ruleFile(value any, _ string) error {
+	s := fmt.Sprint(value)
+	info, err := os.Stat(s)
+	if err != nil || info.IsDir() {
+		return fmt.Errorf("must be an existing file, got '%s'", s)
+	}
+	return nil
+}
+
+func ruleDir(value any, _ string) error {
+	s := fmt.Sprint(value)
+	info, err := os.Stat(s)
+	if err != nil || !info.IsDir() {
+		return fmt.Errorf("must be an existing directory, got '%s'", s)
+	}
+	return nil
+}
+
+func ruleCIDR(value any, _ string) error {
+	s := fmt.Sprint(value)
+	if _, _, err := net.ParseCIDR(s); err != nil {
+		return fmt.Errorf("must be a CIDR address such as 10.0.0.0/8, got '%s'", s)
+	}
+	return nil
+}
@@ -0,0 +1,354 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type testValidateServer struct {
+	BindAddr string        `validate:"required,hostport"`
+	Timeout  time.Duration `validate:"min=1s,max=1m"`
+	CertFile string        `validate:"file"`
+}
+
+type testValidateConfig struct {
+	Name     string   `validate:"required"`
+	Level    string   `validate:"oneof=debug info error"`
+	Workers  int      `validate:"min=1,max=10"`
+	Rate     float64  `validate:"min=0.5"`
+	Ratio    float64  `validate:"gt=0,lt=1"`
+	Hosts    []string `validate:"min=1,hostport"`
+	Networks []string `validate:"cidr"`
+	Endpoint string   `validate:"url"`
+	Cache    string   `validate:"dir"`
+	Server   testValidateServer
+	Ignored  func()
+}
+
+func validTestValidateConfig(t *testing.T) *testValidateConfig {
+	dir := t.TempDir()
+	cert := filepath.Join(dir, "cert.pem")
+	if err := os.WriteFile(cert, []byte("cert"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	return &testValidateConfig{
+		Name:     "app",
+		Level:    "INFO",
+		Workers:  4,
+		Rate:     1,
+		Ratio:    0.5,
+		Hosts:    []string{"localhost:8080", ":9090"},
+		Networks: []string{"10.0.0.0/8"},
+		Endpoint: "https://example.com/api",
+		Cache:    dir,
+		Server: testValidateServer{
+			BindAddr: "localhost:8080",
+			Timeout:  10 * time.Second,
+			CertFile: cert,
+		},
+	}
+}
+
+func TestValidateStruct(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *testValidateConfig)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(c *testValidateConfig) {},
+		},
+		{
+			name: "optional fields empty",
+			modify: func(c *testValidateConfig) {
+				c.Level = ""
+				c.Networks = nil
+				c.Endpoint = ""
+				c.Cache = ""
+				c.Server.CertFile = ""
+			},
+		},
+		{
+			name:    "required whitespace",
+			modify:  func(c *testValidateConfig) { c.Name = "  " },
+			wantErr: "'name' is required",
+		},
+		{
+			name:    "oneof",
+			modify:  func(c *testValidateConfig) { c.Level = "trace" },
+			wantErr: "'level' must be one of: debug, info, error, got 'trace'",
+		},
+		{
+			name:    "min int",
+			modify:  func(c *testValidateConfig) { c.Workers = 0 },
+			wantErr: "'workers' must be at least 1, got 0",
+		},
+		{
+			name:    "max int",
+			modify:  func(c *testValidateConfig) { c.Workers = 11 },
+			wantErr: "'workers' must be at most 10, got 11",
+		},
+		{
+			name:    "min float",
+			modify:  func(c *testValidateConfig) { c.Rate = 0 },
+			wantErr: "'rate' must be at least 0.5, got 0",
+		},
+		{
+			name:    "gt",
+			modify:  func(c *testValidateConfig) { c.Ratio = 0 },
+			wantErr: "'ratio' must be greater than 0, got 0",
+		},
+		{
+			name:    "lt",
+			modify:  func(c *testValidateConfig) { c.Ratio = 1 },
+			wantErr: "'ratio' must be less than 1, got 1",
+		},
+		{
+			name:    "min slice length",
+			modify:  func(c *testValidateConfig) { c.Hosts = nil },
+			wantErr: "'hosts' must have a length of at least 1, got 0",
+		},
+		{
+			name:    "hostport element",
+			modify:  func(c *testValidateConfig) { c.Hosts = []string{"localhost:8080", "localhost"} },
+			wantErr: "'hosts' must be a host:port address, got 'localhost'",
+		},
+		{
+			name:    "hostport port",
+			modify:  func(c *testValidateConfig) { c.Server.BindAddr = "localhost:99999" },
+			wantErr: "'server-bind-addr' must have a port between 0 and 65535",
+		},
+		{
+			name:    "cidr",
+			modify:  func(c *testValidateConfig) { c.Networks = []string{"10.0.0.1"} },
+			wantErr: "'networks' must be a CIDR address",
+		},
+		{
+			name:    "url",
+			modify:  func(c *testValidateConfig) { c.Endpoint = "example.com" },
+			wantErr: "'endpoint' must be an absolute URL, got 'example.com'",
+		},
+		{
+			name:    "dir",
+			modify:  func(c *testValidateConfig) { c.Cache = "/nonexistent" },
+			wantErr: "'cache' must be an existing directory",
+		},
+		{
+			name:    "file",
+			modify:  func(c *testValidateConfig) { c.Server.CertFile = c.Cache },
+			wantErr: "'server-cert-file' must be an existing file",
+		},
+		{
+			name:    "min duration",
+			modify:  func(c *testValidateConfig) { c.Server.Timeout = 0 },
+			wantErr: "'server-timeout' must be at least 1s, got 0s",
+		},
+		{
+			name:    "max duration",
+			modify:  func(c *testValidateConfig) { c.Server.Timeout = time.Hour },
+			wantErr: "'server-timeout' must be at most 1m0s, got 1h0m0s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestValidateConfig(t)
+			tt.modify(cfg)
+
+			err := ValidateStruct(cfg, nil)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateStruct() error = %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateStruct() error = %v, want to contain %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateStruct_AllViolations(t *testing.T) {
+	cfg := validTestValidateConfig(t)
+	cfg.Name = ""
+	cfg.Workers = 20
+	cfg.Server.BindAddr = ""
+
+	err := ValidateStruct(cfg, nil)
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("ValidateStruct() error = %v, want *ValidationError", err)
+	}
+
+	var keys []string
+	for _, e := range verr.Errors {
+		keys = append(keys, e.Key)
+	}
+	want := []string{"name", "workers", "server-bind-addr"}
+	if fmt.Sprint(keys) != fmt.Sprint(want) {
+		t.Errorf("violation keys = %v, want %v", keys, want)
+	}
+
+	var ferr *FieldError
+	if !errors.As(err, &ferr) || ferr.Field != "Name" || ferr.Rule != "required" {
+		t.Errorf("first violation = %+v, want Name/required", ferr)
+	}
+}
+
+func TestValidateStruct_FlagNames(t *testing.T) {
+	cfg := validTestValidateConfig(t)
+	cfg.Workers = 0
+	cfg.Hosts = []string{"invalid"}
+	cfg.Server.Timeout = 0
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.IntVar(&cfg.Workers, "app-workers", cfg.Workers, "")
+	fs.StringSliceVar(&cfg.Hosts, "app-hosts", cfg.Hosts, "")
+	fs.DurationVar(&cfg.Server.Timeout, "app-server-timeout", cfg.Server.Timeout, "")
+
+	err := ValidateStruct(cfg, fs)
+	if err == nil {
+		t.Fatal("ValidateStruct() expected error")
+	}
+
+	for _, want := range []string{"'app-workers'", "'app-hosts'", "'app-server-timeout'"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateStruct() error = %v, want to contain %v", err, want)
+		}
+	}
+}
+
+func TestValidateStruct_Cycle(t *testing.T) {
+	type node struct {
+		Name string `validate:"required"`
+		Next *node
+	}
+
+	n := &node{}
+	n.Next = n
+
+	err := ValidateStruct(n, nil)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Errors) != 1 {
+		t.Errorf("ValidateStruct() error = %v, want 1 violation", err)
+	}
+}
+
+func TestValidateStruct_InvalidRules(t *testing.T) {
+	cfg := &struct {
+		Name  string `validate:"unknown"`
+		Count int    `validate:"min=abc"`
+	}{Name: "name"}
+
+	err := ValidateStruct(cfg, nil)
+	for _, want := range []string{"unknown validation rule 'unknown'", "invalid rule 'min=abc'"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateStruct() error = %v, want to contain %v", err, want)
+		}
+	}
+}
+
+func TestValidateStruct_InvalidTarget(t *testing.T) {
+	for _, target := range []any{nil, testValidateConfig{}, (*testValidateConfig)(nil)} {
+		if err := ValidateStruct(target, nil); err == nil || !strings.Contains(err.Error(), "non-nil pointer to a struct") {
+			t.Errorf("ValidateStruct(%T) error = %v, want target error", target, err)
+		}
+	}
+}
+
+func TestRegisterRule(t *testing.T) {
+	RegisterRule("even", func(value any, _ string) error {
+		if n, ok := value.(int); ok && n%2 != 0 {
+			return fmt.Errorf("must be even, got %d", n)
+		}
+		return nil
+	})
+
+	cfg := &struct {
+		Count int `validate:"even"`
+	}{Count: 3}
+
+	err := ValidateStruct(cfg, nil)
+	if err == nil || !strings.Contains(err.Error(), "'count' must be even, got 3") {
+		t.Errorf("ValidateStruct() error = %v, want custom rule violation", err)
+	}
+
+	cfg.Count = 4
+	if err := ValidateStruct(cfg, nil); err != nil {
+		t.Errorf("ValidateStruct() error = %v", err)
+	}
+}
+
+func TestValidateStruct_WithRule(t *testing.T) {
+	odd := func(value any, _ string) error {
+		if n, ok := value.(int); ok && n%2 == 0 {
+			return fmt.Errorf("must be odd, got %d", n)
+		}
+		return nil
+	}
+
+	cfg := &struct {
+		Count int `validate:"odd"`
+	}{Count: 4}
+
+	err := ValidateStruct(cfg, nil, WithRule("odd", odd))
+	if err == nil || !strings.Contains(err.Error(), "'count' must be odd, got 4") {
+		t.Errorf("ValidateStruct() error = %v, want custom rule violation", err)
+	}
+
+	err = ValidateStruct(cfg, nil)
+	if err == nil || !strings.Contains(err.Error(), "unknown validation rule 'odd'") {
+		t.Errorf("ValidateStruct() error = %v, want the rule to be unknown without WithRule", err)
+	}
+}
+
+func TestValidateComponent(t *testing.T) {
+	c := &testComponent{name: "Test", key: "test-port"}
+
+	// validating must not write the component, which may be read meanwhile
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := ValidateComponent(c)
+			if err == nil || !strings.Contains(err.Error(), "'test-port' must be at least 1, got 0") {
+				t.Errorf("ValidateComponent() error = %v, want the violation named after the flag", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConfigLoader_LoadInto_Validate(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		os.Args = originalArgs
+	}()
+
+	os.Args = []string{"testapp", "--app-name", "", "--port", "70000"}
+
+	cfg := &struct {
+		Port int `validate:"min=1,max=65535"`
+	}{}
+	_, err := NewConfigLoader().LoadInto(cfg)
+
+	for _, want := range []string{"'app-name' is required", "'port' must be at most 65535, got 70000"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadInto() error = %v, want to contain %v", err, want)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/pflag"
+)
+
+// reloadDebounce is how long the watcher waits for file events to settle
+// before reloading, as editors often write a file in several steps.
+const reloadDebounce = 100 * time.Millisecond
+
+// Change describes a configuration key whose value changed during a reload.
+type Change struct {
+	Key string
+	Old string
+	New string
+}
+
+// ChangeFunc is called after a successful reload with the keys that changed.
+type ChangeFunc func(changes []Change)
+
+// Subscribe registers fn to be called after every successful reload that
+// changed at least one value. The next reload waits for fn to return, so fn
+// can copy the values bound to the flags without racing with it, e.g. to
+// swap them into what requests read, but must not call Reload itself.
+func (cl *ConfigLoader) Subscribe(fn ChangeFunc) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.subscribers = append(cl.subscribers, fn)
+}
+
+// OnReloadError registers fn to be called when a reload triggered by the
+// watcher fails. The previous configuration is kept when that happens.
+func (cl *ConfigLoader) OnReloadError(fn func(error)) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.onError = append(cl.onError, fn)
+}
+
+// Reload re-reads the environment, the config file and the providers,
+// validates the result and updates the values bound to every flag. Flags given
+// on the command line keep their value. If the new configuration is invalid it
+// is rejected, the previous values are kept and an error is returned.
+//
+// The new values are validated in copies of the global configuration and of
+// the registered components, which are only written once they're valid. The
+// variables bound by the flagSets given to LoadConfig can't be copied: they're
+// written before their validators run, and restored if validation fails.
+//
+// Reloads run one at a time. The loader's state is only locked to read it and
+// to swap in the result, so validators, providers and the functions called
+// with the result, which run without it, may use the loader.
+func (cl *ConfigLoader) Reload() error {
+	cl.reloadMu.Lock()
+	defer cl.reloadMu.Unlock()
+
+	cl.mu.Lock()
+	fs, opts, defaults := cl.flagSet, cl.opts, cl.defaults
+	config, components := cl.config, cl.components
+	flagSecrets, oldSecrets, oldDeprecations := cl.flagSecrets, cl.secrets, cl.deprecations
+	cl.mu.Unlock()
+
+	if fs == nil {
+		return errors.New("configuration must be loaded before it can be reloaded")
+	}
+
+	previous := snapshotFlags(fs)
+	previousDotenv := cl.dotenvVars()
+
+	staged, stagedConfig, stagedComponents := cl.stage(fs, config, opts, components)
+
+	var secrets map[string]bool
+	var deprecations []Deprecation
+	v, files, err := cl.readConfig(staged, opts)
+	if err == nil && opts.strict {
+		err = cl.checkUnknownKeys(staged, files)
+	}
+	if err == nil {
+		deprecations, err = cl.collectDeprecations(staged, opts.args, files)
+	}
+	if err == nil {
+		secrets, err = cl.applyFlagValues(v, staged, files, defaults)
+	}
+	if err == nil {
+		err = validate(staged, stagedConfig, opts, stagedComponents)
+	}
+	if err == nil {
+		err = setFlags(fs, snapshotFlags(staged))
+	}
+
+	if err != nil {
+		if restoreErr := setFlags(fs, previous); restoreErr != nil {
+			err = errors.Join(err, restoreErr)
+		}
+		cl.setDotenv(previousDotenv)
+		return fmt.Errorf("configuration reload failed: %w", err)
+	}
+
+	maps.Copy(secrets, flagSecrets)
+
+	// redact values that were a secret before or after the reload
+	redact := maps.Clone(secrets)
+	maps.Copy(redact, oldSecrets)
+	changes := diffFlags(fs, previous, redact)
+
+	cl.mu.Lock()
+	cl.viper = v
+	cl.files = files
+	cl.secrets = secrets
+	cl.deprecations = deprecations
+	subscribers := cl.subscribers
+	cl.mu.Unlock()
+
+	cl.reportDeprecations(deprecations, oldDeprecations)
+
+	if len(changes) > 0 {
+		for _, fn := range subscribers {
+			fn(changes)
+		}
+	}
+
+	return nil
+}
+
+// stage returns a flag set like fs in which the global configuration and the
+// components are bound to copies, along with the copies, so a reload can be
+// validated before it's applied. The other flags are the flags of fs.
+func (cl *ConfigLoader) stage(fs *pflag.FlagSet, config *Config, opts *loadOptions, components []component) (*pflag.FlagSet, *Config, []component) {
+	stagedConfig := *config
+	stagedComponents := make([]component, len(components))
+	configurables := make([]Configurable, len(components))
+	for i, c := range components {
+		stagedComponents[i] = component{name: c.name, configurable: detach(c.configurable)}
+		configurables[i] = stagedComponents[i].configurable
+	}
+
+	staged := cl.newFlagSet(&stagedConfig, opts, configurables, nil)
+	fs.VisitAll(func(f *pflag.Flag) {
+		if sf := staged.Lookup(f.Name); sf != nil {
+			sf.Changed = f.Changed
+			return
+		}
+		staged.AddFlag(f)
+	})
+	staged.SetNormalizeFunc(aliasNormalizer(staged, normalizeFlags))
+
+	return staged, &stagedConfig, stagedComponents
+}
+
+// Close stops watching the config file, the providers and signals.
+func (cl *ConfigLoader) Close() error {
+	cl.mu.Lock()
+	w := cl.watcher
+	cl.watcher = nil
+	cl.mu.Unlock()
+
+	if w == nil {
+		return nil
+	}
+
+	return w.close()
+}
+
+// setFlags sets every flag to the given values, e.g. back to the values
+// recorded by snapshotFlags.
+func setFlags(fs *pflag.FlagSet, values map[string]any) error {
+	var errs []error
+	fs.VisitAll(func(f *pflag.Flag) {
+		value, ok := values[f.Name]
+		if !ok || reflect.DeepEqual(flagValue(f), value) {
+			return
+		}
+		if err := setFlagValue(f, value); err != nil {
+			errs = append(errs, fmt.Errorf("failed to set '%s': %w", f.Name, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// diffFlags returns the flags whose value differs from previous. The values
+// of secrets and of keys that look like secrets are redacted.
+func diffFlags(fs *pflag.FlagSet, previous map[string]any, secrets map[string]bool) []Change {
+	var changes []Change
+	fs.VisitAll(func(f *pflag.Flag) {
+		current := flagValue(f)
+		if reflect.DeepEqual(current, previous[f.Name]) {
+			return
+		}
+
+		change := Change{
+			Key: f.Name,
+			Old: formatFlagValue(previous[f.Name]),
+			New: formatFlagValue(current),
+		}
+		switch {
+		case secrets[f.Name]:
+			change.Old, change.New = Redacted, Redacted
+		case isSensitiveKey(f.Name):
+			change.Old, change.New = redactedOrEmpty(change.Old), redactedOrEmpty(change.New)
+		}
+		changes = append(changes, change)
+	})
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
+
+// redactedOrEmpty returns Redacted, or value if it's empty.
+func redactedOrEmpty(value string) string {
+	if value == "" {
+		return value
+	}
+	return Redacted
+}
+
+// formatFlagValue formats a value returned by flagValue.
+func formatFlagValue(value any) string {
+	if values, ok := value.([]string); ok {
+		return strings.Join(values, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// watcher reloads the configuration on config file changes, provider
+// changes and SIGHUP.
+type watcher struct {
+	fsw     *fsnotify.Watcher
+	signals chan os.Signal
+	done    chan struct{}
+	stopped chan struct{}
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// watch starts watching the config file used by the last load, the
+// providers and SIGHUP.
+func (cl *ConfigLoader) watch() error {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if cl.watcher != nil {
+		return nil
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	paths := cl.watchedFiles()
+	for _, path := range paths {
+		// a file may be a symlink to a file of another directory, whose
+		// changes are seen by watching that directory
+		if target, err := filepath.EvalSymlinks(path); err == nil && target != path {
+			paths = append(paths, target)
+		}
+	}
+
+	files := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, path := range paths {
+		files[path] = true
+
+		// watch the directory rather than the file so changes made by
+		// replacing the file, as editors and Kubernetes do, are seen,
+		// as well as optional layers that don't exist yet
+		dir := filepath.Dir(path)
+		if dirs[dir] {
+			continue
+		}
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		if err := fsw.Add(dir); err != nil {
+			_ = fsw.Close()
+			return err
+		}
+		dirs[dir] = true
+	}
+
+	w := &watcher{
+		fsw:     fsw,
+		signals: make(chan os.Signal, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	signal.Notify(w.signals, syscall.SIGHUP)
+
+	var ctx context.Context
+	ctx, w.cancel = context.WithCancel(context.Background())
+
+	cl.watcher = w
+	go cl.runWatcher(w, files)
+	cl.watchProviders(ctx, w)
+
+	return nil
+}
+
+// runWatcher reloads the configuration until the watcher is closed.
+func (cl *ConfigLoader) runWatcher(w *watcher, files map[string]bool) {
+	defer close(w.stopped)
+
+	timer := time.NewTimer(reloadDebounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			timer.Stop()
+			return
+		case <-w.signals:
+			cl.reloadAndReport()
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			// Kubernetes updates a ConfigMap or Secret volume by swapping
+			// its DirDataLink symlink, which the files link through
+			path, err := filepath.Abs(event.Name)
+			if err != nil || !files[path] && filepath.Base(path) != DirDataLink {
+				continue
+			}
+			if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Rename) {
+				timer.Reset(reloadDebounce)
+			}
+		case <-timer.C:
+			cl.reloadAndReport()
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			cl.reportError(fmt.Errorf("config watcher error: %w", err))
+		}
+	}
+}
+
+// reloadAndReport reloads the configuration and reports any error.
+func (cl *ConfigLoader) reloadAndReport() {
+	if err := cl.Reload(); err != nil {
+		cl.reportError(err)
+	}
+}
+
+// reportError calls the functions registered with OnReloadError.
+func (cl *ConfigLoader) reportError(err error) {
+	cl.mu.Lock()
+	handlers := cl.onError
+	cl.mu.Unlock()
+
+	for _, fn := range handlers {
+		fn(err)
+	}
+}
+
+// close stops the watcher and waits for it to exit.
+func (w *watcher) close() error {
+	signal.Stop(w.signals)
+	w.cancel()
+	close(w.done)
+	<-w.stopped
+	w.wg.Wait()
+	return w.fsw.Close()
+}
@@ -0,0 +1,363 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func writeConfigFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func loadWatchTest(t *testing.T, dir string, options ...LoadOption) (*ConfigLoader, *Config, *string, *[]string) {
+	t.Helper()
+
+	originalArgs := os.Args
+	t.Cleanup(func() {
+		os.Args = originalArgs
+	})
+	os.Args = []string{"testapp", "--env-name", "test"}
+
+	var level string
+	var origins []string
+
+	loader := NewConfigLoader(WithConfigPaths(dir))
+	t.Cleanup(func() {
+		_ = loader.Close()
+	})
+
+	options = append([]LoadOption{WithConfigFile(true)}, options...)
+	config, err := loader.LoadConfig(options, func(fs *pflag.FlagSet) {
+		fs.StringVar(&level, "log-level", "INFO", "Log level")
+		fs.StringSliceVar(&origins, "origins", []string{"*"}, "Allowed origins")
+	})
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	return loader, config, &level, &origins
+}
+
+func TestConfigLoader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "config.test.toml", `log-level = "DEBUG"
+origins = ["https://a.example.com"]
+`)
+
+	loader, _, level, origins := loadWatchTest(t, dir)
+
+	var got []Change
+	loader.Subscribe(func(changes []Change) {
+		got = changes
+	})
+
+	writeConfigFile(t, dir, "config.test.toml", `log-level = "WARN"
+origins = ["https://a.example.com", "https://b.example.com"]
+`)
+
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if *level != "WARN" {
+		t.Errorf("log-level = %v, want WARN", *level)
+	}
+	if len(*origins) != 2 {
+		t.Errorf("origins = %v, want 2 origins", *origins)
+	}
+
+	want := []Change{
+		{Key: "log-level", Old: "DEBUG", New: "WARN"},
+		{Key: "origins", Old: "https://a.example.com", New: "https://a.example.com,https://b.example.com"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("changes = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("changes[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestConfigLoader_Reload_RemovedKeyUsesDefault(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "config.test.toml", `log-level = "DEBUG"
+origins = ["https://a.example.com"]
+`)
+
+	loader, _, level, origins := loadWatchTest(t, dir)
+
+	writeConfigFile(t, dir, "config.test.toml", `log-level = "DEBUG"`)
+
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if *level != "DEBUG" {
+		t.Errorf("log-level = %v, want DEBUG", *level)
+	}
+	if len(*origins) != 1 || (*origins)[0] != "*" {
+		t.Errorf("origins = %v, want default [*]", *origins)
+	}
+}
+
+func TestConfigLoader_Reload_RedactsSensitiveKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "config.test.toml", `db-password = "old"`)
+
+	loader := NewConfigLoader(WithConfigPaths(dir))
+	_, err := loader.LoadConfig([]LoadOption{WithArgs([]string{"--env-name", "test"}), WithConfigFile(true)}, func(fs *pflag.FlagSet) {
+		fs.String("db-password", "", "Database password")
+		fs.String("api-token", "", "API token")
+	})
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	var got []Change
+	loader.Subscribe(func(changes []Change) {
+		got = changes
+	})
+
+	writeConfigFile(t, dir, "config.test.toml", `db-password = "new"
+api-token = "token"
+`)
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	want := []Change{
+		{Key: "api-token", Old: "", New: Redacted},
+		{Key: "db-password", Old: Redacted, New: Redacted},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("changes = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("changes[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestConfigLoader_Reload_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		errContain string
+	}{
+		{
+			name: "global validation fails",
+			content: `app-name = ""
+log-level = "WARN"
+`,
+			errContain: "'app-name' is required",
+		},
+		{
+			name:       "validator fails",
+			content:    `log-level = "LOUD"`,
+			errContain: "invalid log level",
+		},
+		{
+			name:       "malformed file",
+			content:    `log-level = `,
+			errContain: "failed to read config file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeConfigFile(t, dir, "config.test.toml", `log-level = "DEBUG"`)
+
+			// level is nil during the initial load, which is always valid
+			var level *string
+			loader, config, l, _ := loadWatchTest(t, dir, WithValidators(func() error {
+				if level != nil && *level != "DEBUG" && *level != "WARN" {
+					return errors.New("invalid log level")
+				}
+				return nil
+			}))
+			level = l
+
+			notified := false
+			loader.Subscribe(func(changes []Change) {
+				notified = true
+			})
+
+			writeConfigFile(t, dir, "config.test.toml", tt.content)
+
+			err := loader.Reload()
+			if err == nil || !strings.Contains(err.Error(), tt.errContain) {
+				t.Fatalf("Reload() error = %v, want to contain %v", err, tt.errContain)
+			}
+
+			if *level != "DEBUG" {
+				t.Errorf("log-level = %v, want previous value DEBUG", *level)
+			}
+			if config.AppName != DefaultAppName {
+				t.Errorf("AppName = %v, want previous value %v", config.AppName, DefaultAppName)
+			}
+			if notified {
+				t.Error("subscribers were notified of a rejected reload")
+			}
+		})
+	}
+}
+
+func TestConfigLoader_Reload_Staged(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "config.test.toml", `test-port = 8080`)
+
+	component := &testComponent{name: "Test", key: "test-port", Port: 1}
+	loader := NewConfigLoader(WithConfigPaths(dir)).Register(component)
+	t.Cleanup(func() {
+		_ = loader.Close()
+	})
+
+	// the validator sees what requests would read while the reload is
+	// validated, config is nil during the initial load
+	var config *Config
+	var seenPort int
+	var seenAppName string
+	config, err := loader.LoadConfig([]LoadOption{
+		WithArgs([]string{"--env-name", "test"}),
+		WithConfigFile(true),
+		WithValidators(func() error {
+			seenPort = component.Port
+			if config != nil {
+				seenAppName = config.AppName
+			}
+			return nil
+		}),
+	})
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	writeConfigFile(t, dir, "config.test.toml", "test-port = 0\napp-name = \"\"")
+	if err := loader.Reload(); err == nil {
+		t.Fatal("Reload() error = nil, want the invalid values rejected")
+	}
+	if seenPort != 8080 || seenAppName != DefaultAppName {
+		t.Errorf("validated with live values port = %v, app-name = %q, want the previous values", seenPort, seenAppName)
+	}
+	if component.Port != 8080 || config.AppName != DefaultAppName {
+		t.Errorf("port = %v, app-name = %q after a rejected reload, want the previous values", component.Port, config.AppName)
+	}
+
+	writeConfigFile(t, dir, "config.test.toml", `test-port = 9090`)
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if component.Port != 9090 {
+		t.Errorf("port = %v after the reload, want 9090", component.Port)
+	}
+}
+
+func TestConfigLoader_Reload_UsesLoader(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "config.test.toml", `log-level = "DEBUG"`)
+
+	// loader is nil during the initial load
+	var loader *ConfigLoader
+	l, _, _, _ := loadWatchTest(t, dir, WithValidators(func() error {
+		if loader != nil {
+			_ = loader.Effective()
+		}
+		return nil
+	}))
+	loader = l
+	loader.Subscribe(func([]Change) {
+		_ = loader.Viper()
+	})
+
+	writeConfigFile(t, dir, "config.test.toml", `log-level = "WARN"`)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- loader.Reload()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Reload() deadlocked calling the loader from a validator")
+	}
+}
+
+func TestConfigLoader_Reload_NotLoaded(t *testing.T) {
+	loader := NewConfigLoader()
+	if err := loader.Reload(); err == nil {
+		t.Error("Reload() expected error before LoadConfig")
+	}
+}
+
+func TestConfigLoader_Watch(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "config.test.toml", `log-level = "DEBUG"`)
+
+	loader, _, _, _ := loadWatchTest(t, dir, WithWatch(true))
+
+	changed := make(chan []Change, 1)
+	loader.Subscribe(func(changes []Change) {
+		changed <- changes
+	})
+
+	writeConfigFile(t, dir, "config.test.toml", `log-level = "ERROR"`)
+
+	select {
+	case changes := <-changed:
+		if len(changes) != 1 || changes[0].Key != "log-level" || changes[0].New != "ERROR" {
+			t.Errorf("changes = %v, want log-level changed to ERROR", changes)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for config reload")
+	}
+
+	if err := loader.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestConfigLoader_Watch_DataLinkSwap(t *testing.T) {
+	dir := t.TempDir()
+	writeDataDir(t, dir, "1", map[string]string{"config.test.toml": `log-level = "DEBUG"`})
+
+	loader, _, level, _ := loadWatchTest(t, dir, WithWatch(true))
+	if *level != "DEBUG" {
+		t.Fatalf("log-level = %v, want DEBUG", *level)
+	}
+
+	changed := make(chan []Change, 1)
+	loader.Subscribe(func(changes []Change) {
+		changed <- changes
+	})
+
+	// the file itself is a symlink that doesn't change, only ..data does
+	writeDataDir(t, dir, "2", map[string]string{"config.test.toml": `log-level = "ERROR"`})
+
+	select {
+	case changes := <-changed:
+		if len(changes) != 1 || changes[0].Key != "log-level" || changes[0].New != "ERROR" {
+			t.Errorf("changes = %v, want log-level changed to ERROR", changes)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the ..data swap to reload the config")
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/alexferl/golib/config"
+	"github.com/alexferl/golib/featureflags"
+	"github.com/labstack/echo/v4"
+)
+
+// Run with FEATURES='{"new-checkout": {"enabled": true, "percentage": 50}}'
+// and request http://localhost:8080/checkout?user=42. With
+// config.WithConfigFile(true), the flags are read from the [features] table
+// of the config files too, and reloaded when they change.
+func main() {
+	flags := featureflags.New()
+
+	_, err := config.NewConfigLoader().Register(flags).LoadConfig([]config.LoadOption{config.WithWatch(true)})
+	if err != nil {
+		var helpErr *config.HelpError
+		if errors.As(err, &helpErr) {
+			fmt.Print(helpErr.Usage)
+			os.Exit(0)
+		}
+		log.Fatal(err)
+	}
+
+	e := echo.New()
+	e.Use(flags.Middleware(
+		featureflags.WithAttribute(featureflags.AttributeUser, func(c echo.Context) string {
+			return c.QueryParam("user")
+		}),
+	))
+
+	e.GET("/checkout", func(c echo.Context) error {
+		if flags.Enabled(c, "new-checkout") {
+			return c.String(http.StatusOK, "new checkout")
+		}
+		return c.String(http.StatusOK, "checkout")
+	})
+
+	log.Fatal(e.Start("localhost:8080"))
+}
@@ -0,0 +1,240 @@
+// Package featureflags evaluates feature flags defined in the configuration
+// loaded by config.ConfigLoader, with percentage rollouts and attribute-based
+// targeting, and exposes them to Echo handlers.
+package featureflags
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	Features = "features"
+)
+
+const (
+	// AttributeUser is the attribute rollouts are based on by default.
+	AttributeUser = "user"
+
+	// AttributeTenant is the attribute conventionally holding the tenant
+	// of a request.
+	AttributeTenant = "tenant"
+
+	// AttributeHeaderPrefix prefixes attributes read from a request header,
+	// e.g. "header:X-Beta".
+	AttributeHeaderPrefix = "header:"
+)
+
+// Flag is the definition of a feature flag. In a config file, a flag is
+// either a boolean or a table:
+//
+//	[features]
+//	dark-mode = true
+//
+//	[features.new-checkout]
+//	enabled = true
+//	percentage = 25
+//	targets = [{ attribute = "tenant", values = ["acme"] }]
+type Flag struct {
+	// Enabled turns the flag on. A disabled flag is off for everyone.
+	Enabled bool `json:"enabled"`
+
+	// Percentage rolls the flag out to a percentage, from 0 to 100, of the
+	// values of the RolloutBy attribute. A value is always in or out of the
+	// rollout, and stays in it when the percentage grows.
+	// Optional. When nil, the flag is on for everyone, or only for its
+	// targets if it has some.
+	Percentage *float64 `json:"percentage,omitempty"`
+
+	// RolloutBy is the attribute the rollout is based on.
+	// Optional. Default value "user".
+	RolloutBy string `json:"rollout-by,omitempty"`
+
+	// Targets turn the flag on for the requests they match, regardless of
+	// the rollout.
+	// Optional. Default value empty slice.
+	Targets []Target `json:"targets,omitempty"`
+}
+
+// Target matches the requests whose attribute has one of the values.
+type Target struct {
+	// Attribute is the name of the attribute, e.g. "user", "tenant" or
+	// "header:X-Beta".
+	Attribute string `json:"attribute"`
+
+	// Values are compared with the value of the attribute.
+	Values []string `json:"values"`
+}
+
+// Attributes are the values flags are evaluated with, e.g.
+// Attributes{"user": "42", "tenant": "acme"}.
+type Attributes map[string]string
+
+// Flags holds the feature flags set under the "features" key. It's safe for
+// concurrent use, and reloads are applied atomically.
+type Flags struct {
+	flags atomic.Pointer[map[string]Flag]
+}
+
+// New returns Flags without any flag, until a configuration is loaded by a
+// config.ConfigLoader the Flags are registered with.
+func New() *Flags {
+	f := &Flags{}
+	f.flags.Store(&map[string]Flag{})
+	return f
+}
+
+// FlagSet returns a pflag.FlagSet for CLI configuration. Its single flag,
+// "features", is set from the tables of config files and providers, or from
+// JSON in environment variables and on the command line, e.g.
+// FEATURES='{"new-checkout": {"enabled": true, "percentage": 10}}'.
+func (f *Flags) FlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("Feature Flags", pflag.ContinueOnError)
+
+	fs.Var((*flagsValue)(f), Features, "Feature flag definitions, as JSON")
+
+	return fs
+}
+
+// Get returns the definition of the flag name.
+func (f *Flags) Get(name string) (Flag, bool) {
+	flag, ok := (*f.flags.Load())[strings.ToLower(name)]
+	return flag, ok
+}
+
+// Names returns the names of the flags, sorted.
+func (f *Flags) Names() []string {
+	return slices.Sorted(maps.Keys(*f.flags.Load()))
+}
+
+// EnabledFor reports whether the flag name is on for attrs, e.g. outside of
+// requests. Unknown flags are off.
+func (f *Flags) EnabledFor(name string, attrs Attributes) bool {
+	return f.enabled(name, func(attribute string) string { return attrs[attribute] })
+}
+
+// enabled reports whether the flag name is on for the attributes returned by
+// attr.
+func (f *Flags) enabled(name string, attr func(attribute string) string) bool {
+	name = strings.ToLower(name)
+	flag, ok := (*f.flags.Load())[name]
+	if !ok || !flag.Enabled {
+		return false
+	}
+
+	for _, target := range flag.Targets {
+		if slices.Contains(target.Values, attr(target.Attribute)) {
+			return true
+		}
+	}
+
+	if flag.Percentage == nil {
+		return len(flag.Targets) == 0
+	}
+	return inRollout(name, attr(flag.RolloutBy), *flag.Percentage)
+}
+
+// inRollout reports whether value is in the rollout of the flag name to
+// percentage of the values.
+func inRollout(name, value string, percentage float64) bool {
+	switch {
+	case percentage >= 100:
+		return true
+	case percentage <= 0 || value == "":
+		return false
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name + "/" + value))
+	return float64(h.Sum32()%10000)/100 < percentage
+}
+
+// flagsValue is the pflag.Value of the "features" flag.
+type flagsValue Flags
+
+func (v *flagsValue) String() string {
+	b, _ := json.Marshal(*(*Flags)(v).flags.Load())
+	return string(b)
+}
+
+func (v *flagsValue) Set(s string) error {
+	flags, err := parseFlags([]byte(s))
+	if err != nil {
+		return err
+	}
+	(*Flags)(v).flags.Store(&flags)
+	return nil
+}
+
+// SetMap sets the flags from a table of a config file or provider.
+func (v *flagsValue) SetMap(m map[string]any) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("invalid feature flags: %w", err)
+	}
+	return v.Set(string(b))
+}
+
+func (v *flagsValue) Type() string {
+	return "json"
+}
+
+// parseFlags parses flag definitions from a JSON object whose values are
+// booleans or Flag objects.
+func parseFlags(b []byte) (map[string]Flag, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("invalid feature flags: %w", err)
+	}
+
+	flags := make(map[string]Flag, len(raw))
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(raw)) {
+		flag, err := parseFlag(raw[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid feature flag '%s': %w", name, err))
+			continue
+		}
+		flags[strings.ToLower(name)] = flag
+	}
+
+	return flags, errors.Join(errs...)
+}
+
+// parseFlag parses a flag definition from a boolean or a Flag object.
+func parseFlag(b []byte) (Flag, error) {
+	var enabled bool
+	if err := json.Unmarshal(b, &enabled); err == nil {
+		return Flag{Enabled: enabled}, nil
+	}
+
+	var flag Flag
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&flag); err != nil {
+		return Flag{}, err
+	}
+
+	if flag.Percentage != nil && (*flag.Percentage < 0 || *flag.Percentage > 100) {
+		return Flag{}, fmt.Errorf("percentage must be between 0 and 100, got %g", *flag.Percentage)
+	}
+	if flag.RolloutBy == "" {
+		flag.RolloutBy = AttributeUser
+	}
+	for _, target := range flag.Targets {
+		if target.Attribute == "" {
+			return Flag{}, errors.New("target attribute is required")
+		}
+	}
+
+	return flag, nil
+}
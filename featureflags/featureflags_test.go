@@ -0,0 +1,245 @@
+package featureflags
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alexferl/golib/config"
+)
+
+func newFlags(t *testing.T, definitions string) *Flags {
+	t.Helper()
+
+	f := New()
+	if err := f.FlagSet().Set(Features, definitions); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	return f
+}
+
+func TestFlags_Enabled(t *testing.T) {
+	f := newFlags(t, `{
+		"dark-mode": true,
+		"legacy": false,
+		"disabled": {"enabled": false, "percentage": 100},
+		"beta": {"enabled": true, "targets": [{"attribute": "tenant", "values": ["acme"]}]},
+		"header": {"enabled": true, "targets": [{"attribute": "header:X-Beta", "values": ["1"]}]},
+		"none": {"enabled": true, "percentage": 0, "targets": [{"attribute": "user", "values": ["42"]}]},
+		"all": {"enabled": true, "percentage": 100}
+	}`)
+
+	tests := []struct {
+		name  string
+		flag  string
+		attrs Attributes
+		want  bool
+	}{
+		{name: "boolean on", flag: "dark-mode", want: true},
+		{name: "boolean off", flag: "legacy", want: false},
+		{name: "case insensitive", flag: "Dark-Mode", want: true},
+		{name: "unknown", flag: "unknown", want: false},
+		{name: "disabled", flag: "disabled", attrs: Attributes{"user": "1"}, want: false},
+		{name: "target matches", flag: "beta", attrs: Attributes{"tenant": "acme"}, want: true},
+		{name: "target doesn't match", flag: "beta", attrs: Attributes{"tenant": "other"}, want: false},
+		{name: "header target", flag: "header", attrs: Attributes{"header:X-Beta": "1"}, want: true},
+		{name: "target overrides rollout", flag: "none", attrs: Attributes{"user": "42"}, want: true},
+		{name: "out of rollout", flag: "none", attrs: Attributes{"user": "1"}, want: false},
+		{name: "full rollout without user", flag: "all", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.EnabledFor(tt.flag, tt.attrs); got != tt.want {
+				t.Errorf("EnabledFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlags_Enabled_Rollout(t *testing.T) {
+	f := newFlags(t, `{
+		"quarter": {"enabled": true, "percentage": 25},
+		"half": {"enabled": true, "percentage": 50},
+		"tenants": {"enabled": true, "percentage": 50, "rollout-by": "tenant"}
+	}`)
+
+	quarter, half := 0, 0
+	for i := range 10000 {
+		attrs := Attributes{"user": strconv.Itoa(i)}
+		inQuarter := f.EnabledFor("quarter", attrs)
+		if inQuarter {
+			quarter++
+		}
+		if f.EnabledFor("half", attrs) {
+			half++
+		}
+		if inQuarter != f.EnabledFor("quarter", attrs) {
+			t.Fatalf("Enabled() isn't stable for user %d", i)
+		}
+	}
+
+	if quarter < 2300 || quarter > 2700 {
+		t.Errorf("quarter rollout enabled for %d of 10000 users", quarter)
+	}
+	if half < 4800 || half > 5200 {
+		t.Errorf("half rollout enabled for %d of 10000 users", half)
+	}
+
+	if f.EnabledFor("half", nil) {
+		t.Error("Enabled() = true for a partial rollout without user")
+	}
+	if f.EnabledFor("tenants", Attributes{"user": "1"}) {
+		t.Error("Enabled() = true for a rollout by tenant without tenant")
+	}
+}
+
+func TestFlagSet_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{name: "valid", value: `{"a": true, "b": {"enabled": true, "percentage": 12.5}}`},
+		{name: "invalid json", value: `{`, wantErr: "invalid feature flags"},
+		{name: "percentage out of range", value: `{"a": {"enabled": true, "percentage": 120}}`, wantErr: "percentage must be between 0 and 100, got 120"},
+		{name: "unknown field", value: `{"a": {"enable": true}}`, wantErr: `unknown field "enable"`},
+		{name: "target without attribute", value: `{"a": {"enabled": true, "targets": [{"values": ["x"]}]}}`, wantErr: "target attribute is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFlags(t, `{"previous": true}`)
+			err := f.FlagSet().Set(Features, tt.value)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Set() error = %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Set() error = %v, want %v", err, tt.wantErr)
+			}
+			if !f.EnabledFor("previous", nil) {
+				t.Error("Set() replaced the flags despite the error")
+			}
+		})
+	}
+}
+
+func TestFlags_ConfigLoader(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.local.toml")
+	writeFile(t, file, `[features]
+dark-mode = true
+
+[features.new-checkout]
+enabled = true
+targets = [{ attribute = "tenant", values = ["acme"] }]
+`)
+
+	f := New()
+	loader := config.NewConfigLoader(config.WithConfigPaths(dir)).Register(f)
+	if _, err := loader.LoadConfig([]config.LoadOption{config.WithArgs(nil), config.WithConfigFile(true)}); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if names := f.Names(); strings.Join(names, ",") != "dark-mode,new-checkout" {
+		t.Errorf("Names() = %v, want [dark-mode new-checkout]", names)
+	}
+	if !f.EnabledFor("new-checkout", Attributes{"tenant": "acme"}) {
+		t.Error("Enabled() = false, want true for the targeted tenant")
+	}
+
+	var changed []string
+	loader.Subscribe(func(changes []config.Change) {
+		for _, change := range changes {
+			changed = append(changed, change.Key)
+		}
+	})
+
+	writeFile(t, file, `[features.new-checkout]
+enabled = false
+`)
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if f.EnabledFor("new-checkout", Attributes{"tenant": "acme"}) || f.EnabledFor("dark-mode", nil) {
+		t.Error("Enabled() = true after the flags were turned off")
+	}
+	if len(changed) != 1 || changed[0] != Features {
+		t.Errorf("changes = %v, want [%s]", changed, Features)
+	}
+
+	writeFile(t, file, `[features.new-checkout]
+enabled = true
+percentage = 101
+`)
+	if err := loader.Reload(); err == nil {
+		t.Error("Reload() expected error for an invalid flag")
+	}
+	if _, ok := f.Get("new-checkout"); !ok || f.EnabledFor("new-checkout", Attributes{"user": "1"}) {
+		t.Error("Reload() didn't keep the previous flags")
+	}
+}
+
+func TestFlags_ConfigLoader_RejectedReload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.local.toml")
+	writeFile(t, file, `[features]
+dark-mode = true
+`)
+
+	// the validator sees what requests would read while the reload is validated
+	f := New()
+	enabled := true
+	loader := config.NewConfigLoader(config.WithConfigPaths(dir)).Register(f)
+	if _, err := loader.LoadConfig([]config.LoadOption{
+		config.WithArgs(nil),
+		config.WithConfigFile(true),
+		config.WithValidators(func() error {
+			enabled = enabled && f.EnabledFor("dark-mode", nil)
+			return nil
+		}),
+	}); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	writeFile(t, file, `app-name = ""
+
+[features]
+dark-mode = false
+`)
+	if err := loader.Reload(); err == nil {
+		t.Fatal("Reload() expected error for an empty app name")
+	}
+	if !enabled || !f.EnabledFor("dark-mode", nil) {
+		t.Error("Enabled() = false, want the flags of a rejected reload never applied")
+	}
+}
+
+func TestFlags_ConfigLoader_Env(t *testing.T) {
+	t.Setenv("FEATURES", `{"dark-mode": {"enabled": true, "percentage": 100}}`)
+
+	f := New()
+	loader := config.NewConfigLoader().Register(f)
+	if _, err := loader.LoadConfig([]config.LoadOption{config.WithArgs(nil)}); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if !f.EnabledFor("dark-mode", nil) {
+		t.Error("Enabled() = false, want true from the environment")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,72 @@
+package featureflags
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// contextKey is the key the middleware stores the AttributeFuncs under.
+const contextKey = "featureflags"
+
+// AttributeFunc returns the value of an attribute for a request, e.g. the ID
+// of the authenticated user.
+type AttributeFunc func(c echo.Context) string
+
+// MiddlewareOption configures the middleware.
+type MiddlewareOption func(*middlewareConfig)
+
+type middlewareConfig struct {
+	attributes map[string]AttributeFunc
+}
+
+// WithAttribute sets how the middleware reads the attribute name of a
+// request, e.g. WithAttribute(AttributeUser, userID). Attributes named
+// "header:<name>" are read from the request header without an AttributeFunc.
+func WithAttribute(name string, fn AttributeFunc) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.attributes[name] = fn
+	}
+}
+
+// requestAttributes reads the attributes of a request.
+type requestAttributes struct {
+	attributes map[string]AttributeFunc
+	c          echo.Context
+}
+
+// attr returns the value of the attribute of the request.
+func (r *requestAttributes) attr(attribute string) string {
+	if fn, ok := r.attributes[attribute]; ok {
+		return fn(r.c)
+	}
+	if header, ok := strings.CutPrefix(attribute, AttributeHeaderPrefix); ok {
+		return r.c.Request().Header.Get(header)
+	}
+	return ""
+}
+
+// Middleware returns an Echo middleware reading the attributes of each
+// request with the AttributeFuncs of options, for Enabled.
+func (f *Flags) Middleware(options ...MiddlewareOption) echo.MiddlewareFunc {
+	cfg := &middlewareConfig{attributes: map[string]AttributeFunc{}}
+	for _, option := range options {
+		option(cfg)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(contextKey, cfg.attributes)
+			return next(c)
+		}
+	}
+}
+
+// Enabled reports whether the flag name is on for the request of c, e.g.
+// flags.Enabled(c, "new-checkout"). Without the middleware, only the
+// "header:<name>" attributes are available. Unknown flags are off.
+func (f *Flags) Enabled(c echo.Context, name string) bool {
+	attributes, _ := c.Get(contextKey).(map[string]AttributeFunc)
+	r := &requestAttributes{attributes: attributes, c: c}
+	return f.enabled(name, r.attr)
+}
@@ -0,0 +1,70 @@
+package featureflags
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestFlags_Middleware(t *testing.T) {
+	f := newFlags(t, `{
+		"beta": {"enabled": true, "targets": [{"attribute": "header:X-Beta", "values": ["1"]}]},
+		"new-checkout": {"enabled": true, "targets": [{"attribute": "user", "values": ["42"]}]}
+	}`)
+
+	e := echo.New()
+	e.Use(f.Middleware(WithAttribute(AttributeUser, func(c echo.Context) string {
+		return c.QueryParam("user")
+	})))
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, strconv.FormatBool(f.Enabled(c, "beta"))+","+strconv.FormatBool(f.Enabled(c, "new-checkout")))
+	})
+
+	tests := []struct {
+		name   string
+		target string
+		header string
+		want   string
+	}{
+		{name: "no attributes", target: "/", want: "false,false"},
+		{name: "header", target: "/", header: "1", want: "true,false"},
+		{name: "user", target: "/?user=42", want: "false,true"},
+		{name: "other user", target: "/?user=7", want: "false,false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set("X-Beta", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Body.String() != tt.want {
+				t.Errorf("body = %v, want %v", rec.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestFlags_Enabled_WithoutMiddleware(t *testing.T) {
+	f := newFlags(t, `{
+		"beta": {"enabled": true, "targets": [{"attribute": "header:X-Beta", "values": ["1"]}]},
+		"new-checkout": {"enabled": true, "targets": [{"attribute": "user", "values": ["42"]}]}
+	}`)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Beta", "1")
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+
+	if !f.Enabled(c, "beta") {
+		t.Error("Enabled() = false, want true from the request header")
+	}
+	if f.Enabled(c, "new-checkout") {
+		t.Error("Enabled() = true without the middleware reading the user")
+	}
+}
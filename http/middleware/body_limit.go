@@ -1,8 +1,12 @@
 package middleware
 
 import (
+	"fmt"
+
+	"github.com/alexferl/golib/config"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/labstack/gommon/bytes"
 	"github.com/spf13/pflag"
 )
 
@@ -14,23 +18,52 @@ type BodyLimit struct {
 
 	// MaxSize specifies the maximum request body size (e.g., "1MB", "2KB").
 	// Optional. Default value "1MB".
-	MaxSize string
+	MaxSize string `validate:"required,bytes"`
+}
+
+// validateBytes checks that value is a size accepted by the body limit middleware.
+func validateBytes(value any, _ string) error {
+	if _, err := bytes.Parse(fmt.Sprint(value)); err != nil {
+		return fmt.Errorf("must be a size such as 1MB or 512KB, got '%v'", value)
+	}
+	return nil
 }
 
-// DefaultBodyLimit provides default BodyLimit configuration.
+// DefaultBodyLimit provides default BodyLimit configuration. It is shared by the whole
+// process, use NewBodyLimitConfig to get a copy that can be changed.
 var DefaultBodyLimit = &BodyLimit{
 	Enabled: false,
 	MaxSize: "1MB",
 }
 
+// NewBodyLimitConfig returns a copy of DefaultBodyLimit.
+func NewBodyLimitConfig() *BodyLimit {
+	return DefaultBodyLimit.Clone()
+}
+
+// Clone returns a copy of the configuration.
+func (b *BodyLimit) Clone() *BodyLimit {
+	clone := *b
+	return &clone
+}
+
 const (
 	BodyLimitEnabled = "body-limit-enabled"
 	BodyLimitMaxSize = "body-limit-max-size"
 )
 
+// Validate checks if configuration values are valid. Nothing is checked
+// when the middleware is disabled.
+func (b *BodyLimit) Validate() error {
+	if !b.Enabled {
+		return nil
+	}
+	return config.ValidateComponent(b, config.WithRule("bytes", validateBytes))
+}
+
 // FlagSet returns a pflag.FlagSet for CLI configuration.
 func (b *BodyLimit) FlagSet() *pflag.FlagSet {
-	fs := pflag.NewFlagSet("Body Limit", pflag.ExitOnError)
+	fs := pflag.NewFlagSet("Body Limit", pflag.ContinueOnError)
 
 	fs.BoolVar(&b.Enabled, BodyLimitEnabled, b.Enabled, "Enable request body size limiting")
 	fs.StringVar(&b.MaxSize, BodyLimitMaxSize, b.MaxSize, "Maximum request body size (e.g., 1MB, 2KB)")
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -100,7 +101,7 @@ func TestBodyLimit_FlagSet_DefaultValues(t *testing.T) {
 }
 
 func TestBodyLimit_FlagSet_DisabledByDefault(t *testing.T) {
-	config := DefaultBodyLimit
+	config := NewBodyLimitConfig()
 
 	fs := config.FlagSet()
 
@@ -134,3 +135,45 @@ func TestNewBodyLimit_DefaultConfig(t *testing.T) {
 		t.Fatal("NewBodyLimit() with DefaultBodyLimit returned nil")
 	}
 }
+
+func TestBodyLimit_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *BodyLimit
+		wantErr string
+	}{
+		{
+			name:   "disabled with invalid size",
+			config: &BodyLimit{Enabled: false, MaxSize: "lots"},
+		},
+		{
+			name:   "valid size",
+			config: &BodyLimit{Enabled: true, MaxSize: "2KB"},
+		},
+		{
+			name:    "invalid size",
+			config:  &BodyLimit{Enabled: true, MaxSize: "lots"},
+			wantErr: "'body-limit-max-size' must be a size such as 1MB or 512KB, got 'lots'",
+		},
+		{
+			name:    "empty size",
+			config:  &BodyLimit{Enabled: true},
+			wantErr: "'body-limit-max-size' is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want to contain %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"net/http"
+	"slices"
 
+	"github.com/alexferl/golib/config"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/spf13/pflag"
@@ -20,7 +22,7 @@ type CORS struct {
 
 	// AllowMethods specifies the allowed HTTP methods for CORS requests.
 	// Optional. Default value []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete}.
-	AllowMethods []string
+	AllowMethods []string `validate:"oneof=GET HEAD PUT PATCH POST DELETE OPTIONS CONNECT TRACE"`
 
 	// AllowHeaders specifies the allowed headers for CORS requests.
 	// Optional. Default value []string{}.
@@ -39,13 +41,29 @@ type CORS struct {
 	MaxAge int
 }
 
-// DefaultCORS provides default CORS configuration.
+// DefaultCORS provides default CORS configuration. It is shared by the whole
+// process, use NewCORSConfig to get a copy that can be changed.
 var DefaultCORS = &CORS{
 	Enabled:      false,
 	AllowOrigins: []string{"*"},
 	AllowMethods: []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete},
 }
 
+// NewCORSConfig returns a copy of DefaultCORS.
+func NewCORSConfig() *CORS {
+	return DefaultCORS.Clone()
+}
+
+// Clone returns a deep copy of the configuration.
+func (c *CORS) Clone() *CORS {
+	clone := *c
+	clone.AllowOrigins = slices.Clone(c.AllowOrigins)
+	clone.AllowMethods = slices.Clone(c.AllowMethods)
+	clone.AllowHeaders = slices.Clone(c.AllowHeaders)
+	clone.ExposeHeaders = slices.Clone(c.ExposeHeaders)
+	return &clone
+}
+
 const (
 	CORSEnabled          = "cors-enabled"
 	CORSAllowOrigins     = "cors-allow-origins"
@@ -56,9 +74,18 @@ const (
 	CORSMaxAge           = "cors-max-age"
 )
 
+// Validate checks if configuration values are valid. Nothing is checked
+// when the middleware is disabled.
+func (c *CORS) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	return config.ValidateComponent(c)
+}
+
 // FlagSet returns a pflag.FlagSet for CLI configuration.
 func (c *CORS) FlagSet() *pflag.FlagSet {
-	fs := pflag.NewFlagSet("CORS", pflag.ExitOnError)
+	fs := pflag.NewFlagSet("CORS", pflag.ContinueOnError)
 
 	fs.BoolVar(&c.Enabled, CORSEnabled, c.Enabled, "Enable CORS middleware")
 	fs.StringSliceVar(&c.AllowOrigins, CORSAllowOrigins, c.AllowOrigins, "Allowed origins for CORS requests")
@@ -71,8 +98,17 @@ func (c *CORS) FlagSet() *pflag.FlagSet {
 	return fs
 }
 
-// NewCORS creates a new CORS middleware with the given configuration.
-func NewCORS(config *CORS) echo.MiddlewareFunc {
+// NewCORS creates a new CORS middleware with the given configuration. With
+// WithReload, the allowed origins and the other settings are updated when the
+// configuration is reloaded.
+func NewCORS(config *CORS, options ...Option) echo.MiddlewareFunc {
+	return reloadable("cors-", func() echo.MiddlewareFunc {
+		return newCORS(config.Clone())
+	}, options)
+}
+
+// newCORS creates the Echo CORS middleware of config.
+func newCORS(config *CORS) echo.MiddlewareFunc {
 	return middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     config.AllowOrigins,
 		AllowMethods:     config.AllowMethods,
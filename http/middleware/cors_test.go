@@ -3,6 +3,7 @@ package middleware
 import (
 	"net/http"
 	"reflect"
+	"sync"
 	"testing"
 )
 
@@ -116,6 +117,26 @@ func TestDefaultCORS(t *testing.T) {
 	}
 }
 
+func TestNewCORSConfig(t *testing.T) {
+	config := NewCORSConfig()
+	if config == DefaultCORS {
+		t.Fatal("NewCORSConfig() returned DefaultCORS instead of a copy")
+	}
+	if !reflect.DeepEqual(config, DefaultCORS) {
+		t.Errorf("NewCORSConfig() = %+v, want %+v", config, DefaultCORS)
+	}
+
+	config.AllowOrigins[0] = "https://example.com"
+	config.AllowMethods[0] = http.MethodPost
+
+	if DefaultCORS.AllowOrigins[0] != "*" {
+		t.Errorf("DefaultCORS.AllowOrigins = %v, want [*]", DefaultCORS.AllowOrigins)
+	}
+	if DefaultCORS.AllowMethods[0] != http.MethodGet {
+		t.Errorf("DefaultCORS.AllowMethods = %v, want %v first", DefaultCORS.AllowMethods, http.MethodGet)
+	}
+}
+
 func TestCORS_FlagSet_DefaultValues(t *testing.T) {
 	config := &CORS{
 		Enabled:          true,
@@ -147,7 +168,7 @@ func TestCORS_FlagSet_DefaultValues(t *testing.T) {
 }
 
 func TestCORS_FlagSet_DisabledByDefault(t *testing.T) {
-	config := DefaultCORS
+	config := NewCORSConfig()
 
 	fs := config.FlagSet()
 
@@ -182,3 +203,21 @@ func TestNewCORS_DefaultConfig(t *testing.T) {
 		t.Fatal("NewCORS() with DefaultCORS returned nil")
 	}
 }
+
+func TestCORS_Validate_Concurrent(t *testing.T) {
+	config := NewCORSConfig()
+	config.Enabled = true
+
+	// validating must not write the configuration requests read
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := config.Validate(); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
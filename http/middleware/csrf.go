@@ -3,8 +3,10 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
+	"github.com/alexferl/golib/config"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/spf13/pflag"
@@ -66,6 +68,22 @@ func (m *CSRFSameSiteMode) Type() string {
 	return "string"
 }
 
+// csrfTokenSources lists the sources a CSRF token can be looked up from.
+var csrfTokenSources = []string{"header", "query", "form", "param", "cookie"}
+
+// validateTokenLookup checks that value is a comma-separated list of
+// "<source>:<name>" token lookups.
+func validateTokenLookup(value any, _ string) error {
+	for _, lookup := range strings.Split(fmt.Sprint(value), ",") {
+		source, name, ok := strings.Cut(strings.TrimSpace(lookup), ":")
+		if !ok || name == "" || !slices.Contains(csrfTokenSources, source) {
+			return fmt.Errorf("must be a list of <source>:<name> lookups with source one of: %s, got '%v'",
+				strings.Join(csrfTokenSources, ", "), value)
+		}
+	}
+	return nil
+}
+
 // CSRF holds configuration for Cross-Site Request Forgery protection.
 type CSRF struct {
 	// Enabled indicates whether CSRF middleware is enabled.
@@ -85,7 +103,7 @@ type CSRF struct {
 	// - "form:<name>"
 	// Multiple sources example:
 	// - "header:X-CSRF-Token,query:csrf"
-	TokenLookup string
+	TokenLookup string `validate:"required,token-lookup"`
 
 	// ContextKey specifies the key used to store CSRF token in context.
 	// Optional. Default value "csrf".
@@ -105,7 +123,7 @@ type CSRF struct {
 
 	// CookieMaxAge specifies the max age for the CSRF cookie in seconds.
 	// Optional. Default value 86400.
-	CookieMaxAge int
+	CookieMaxAge int `validate:"min=0"`
 
 	// CookieSecure indicates whether the CSRF cookie should be secure.
 	// Optional. Default value false.
@@ -120,7 +138,8 @@ type CSRF struct {
 	CookieSameSite CSRFSameSiteMode
 }
 
-// DefaultCSRF provides default CSRF configuration.
+// DefaultCSRF provides default CSRF configuration. It is shared by the whole
+// process, use NewCSRFConfig to get a copy that can be changed.
 var DefaultCSRF = &CSRF{
 	Enabled:        false,
 	TokenLength:    32,
@@ -131,6 +150,17 @@ var DefaultCSRF = &CSRF{
 	CookieSameSite: CSRFSameSiteMode(http.SameSiteDefaultMode),
 }
 
+// NewCSRFConfig returns a copy of DefaultCSRF.
+func NewCSRFConfig() *CSRF {
+	return DefaultCSRF.Clone()
+}
+
+// Clone returns a copy of the configuration.
+func (c *CSRF) Clone() *CSRF {
+	clone := *c
+	return &clone
+}
+
 const (
 	CSRFEnabled        = "csrf-enabled"
 	CSRFTokenLength    = "csrf-token-length"
@@ -145,9 +175,18 @@ const (
 	CSRFCookieSameSite = "csrf-cookie-same-site"
 )
 
+// Validate checks if configuration values are valid. Nothing is checked
+// when the middleware is disabled.
+func (c *CSRF) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	return config.ValidateComponent(c, config.WithRule("token-lookup", validateTokenLookup))
+}
+
 // FlagSet returns a pflag.FlagSet for CLI configuration.
 func (c *CSRF) FlagSet() *pflag.FlagSet {
-	fs := pflag.NewFlagSet("CSRF", pflag.ExitOnError)
+	fs := pflag.NewFlagSet("CSRF", pflag.ContinueOnError)
 
 	fs.BoolVar(&c.Enabled, CSRFEnabled, c.Enabled, "Enable CSRF protection middleware")
 	fs.Uint8Var(&c.TokenLength, CSRFTokenLength, c.TokenLength, "Length of the CSRF token")
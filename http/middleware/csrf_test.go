@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -189,7 +190,7 @@ func TestCSRF_FlagSet_DefaultValues(t *testing.T) {
 }
 
 func TestCSRF_FlagSet_DisabledByDefault(t *testing.T) {
-	config := DefaultCSRF
+	config := NewCSRFConfig()
 
 	fs := config.FlagSet()
 
@@ -284,3 +285,54 @@ func TestNewCSRF_DefaultConfig(t *testing.T) {
 		t.Fatal("NewCSRF() with DefaultCSRF returned nil")
 	}
 }
+
+func TestCSRF_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *CSRF)
+		wantErr string
+	}{
+		{
+			name:   "default config enabled",
+			modify: func(c *CSRF) {},
+		},
+		{
+			name:   "multiple lookups",
+			modify: func(c *CSRF) { c.TokenLookup = "header:X-CSRF-Token, form:_csrf" },
+		},
+		{
+			name:    "invalid lookup source",
+			modify:  func(c *CSRF) { c.TokenLookup = "headers:X-CSRF-Token" },
+			wantErr: "'csrf-token-lookup' must be a list of <source>:<name> lookups",
+		},
+		{
+			name:    "missing lookup name",
+			modify:  func(c *CSRF) { c.TokenLookup = "header:" },
+			wantErr: "'csrf-token-lookup' must be a list of <source>:<name> lookups",
+		},
+		{
+			name:    "negative cookie max age",
+			modify:  func(c *CSRF) { c.CookieMaxAge = -1 },
+			wantErr: "'csrf-cookie-max-age' must be at least 0, got -1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := *NewCSRFConfig()
+			config.Enabled = true
+			tt.modify(&config)
+
+			err := config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want to contain %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/alexferl/golib/config"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/spf13/pflag"
@@ -15,15 +16,15 @@ import (
 type RateLimiterMemoryStore struct {
 	// Rate specifies the rate limit per second.
 	// Optional. Default value 0.
-	Rate float64
+	Rate float64 `validate:"gt=0"`
 
 	// Burst specifies the burst limit.
 	// Optional. Default value 0.
-	Burst int
+	Burst int `validate:"min=0"`
 
 	// ExpiresIn specifies the expiration time for rate limit entries.
 	// Optional. Default value 3 minutes.
-	ExpiresIn time.Duration
+	ExpiresIn time.Duration `validate:"min=0s"`
 }
 
 type RateLimiterStore string
@@ -76,7 +77,8 @@ type RateLimiter struct {
 	Memory RateLimiterMemoryStore
 }
 
-// DefaultRateLimiter provides default RateLimiter configuration.
+// DefaultRateLimiter provides default RateLimiter configuration. It is shared by the whole
+// process, use NewRateLimiterConfig to get a copy that can be changed.
 var DefaultRateLimiter = &RateLimiter{
 	Enabled: false,
 	Store:   LimiterStoreMemory,
@@ -87,6 +89,17 @@ var DefaultRateLimiter = &RateLimiter{
 	},
 }
 
+// NewRateLimiterConfig returns a copy of DefaultRateLimiter.
+func NewRateLimiterConfig() *RateLimiter {
+	return DefaultRateLimiter.Clone()
+}
+
+// Clone returns a copy of the configuration.
+func (r *RateLimiter) Clone() *RateLimiter {
+	clone := *r
+	return &clone
+}
+
 const (
 	RateLimiterEnabled       = "rate-limiter-enabled"
 	RateLimiterStoreType     = "rate-limiter-store"
@@ -95,9 +108,18 @@ const (
 	RateLimiterMemoryExpires = "rate-limiter-memory-expires"
 )
 
+// Validate checks if configuration values are valid. Nothing is checked
+// when the middleware is disabled.
+func (r *RateLimiter) Validate() error {
+	if !r.Enabled {
+		return nil
+	}
+	return config.ValidateComponent(r)
+}
+
 // FlagSet returns a pflag.FlagSet for CLI configuration.
 func (r *RateLimiter) FlagSet() *pflag.FlagSet {
-	fs := pflag.NewFlagSet("Rate Limiter", pflag.ExitOnError)
+	fs := pflag.NewFlagSet("Rate Limiter", pflag.ContinueOnError)
 
 	fs.BoolVar(&r.Enabled, RateLimiterEnabled, r.Enabled, "Enable rate limiting middleware")
 	fs.Var(&r.Store, RateLimiterStoreType, fmt.Sprintf("Rate limiter store type\nValues: %s", strings.Join(LimiterStores, ", ")))
@@ -109,16 +131,25 @@ func (r *RateLimiter) FlagSet() *pflag.FlagSet {
 }
 
 // NewRateLimiter creates a new rate limiter middleware with the given configuration.
-func NewRateLimiter(config *RateLimiter) echo.MiddlewareFunc {
-	switch config.Store {
-	case LimiterStoreMemory:
-		s := middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
-			Rate:      rate.Limit(config.Memory.Rate),
-			Burst:     config.Memory.Burst,
-			ExpiresIn: config.Memory.ExpiresIn,
-		})
-
-		return middleware.RateLimiter(s)
+// With WithReload, the limits are updated when the configuration is reloaded,
+// which starts counting the requests of every visitor again.
+func NewRateLimiter(config *RateLimiter, options ...Option) echo.MiddlewareFunc {
+	if config.Store != LimiterStoreMemory {
+		return nil
 	}
-	return nil
+
+	return reloadable("rate-limiter-", func() echo.MiddlewareFunc {
+		return newRateLimiter(config.Clone())
+	}, options)
+}
+
+// newRateLimiter creates the Echo rate limiter middleware of config.
+func newRateLimiter(config *RateLimiter) echo.MiddlewareFunc {
+	s := middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+		Rate:      rate.Limit(config.Memory.Rate),
+		Burst:     config.Memory.Burst,
+		ExpiresIn: config.Memory.ExpiresIn,
+	})
+
+	return middleware.RateLimiter(s)
 }
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -172,7 +173,7 @@ func TestRateLimiter_FlagSet_DefaultValues(t *testing.T) {
 }
 
 func TestRateLimiter_FlagSet_DisabledByDefault(t *testing.T) {
-	config := DefaultRateLimiter
+	config := NewRateLimiterConfig()
 
 	fs := config.FlagSet()
 
@@ -276,3 +277,56 @@ func TestNewRateLimiter_InvalidStore(t *testing.T) {
 		t.Error("NewRateLimiter() with invalid store should return nil")
 	}
 }
+
+func TestRateLimiter_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *RateLimiter
+		wantErr []string
+	}{
+		{
+			name:   "disabled with default config",
+			config: &RateLimiter{Enabled: false, Store: LimiterStoreMemory},
+		},
+		{
+			name: "valid",
+			config: &RateLimiter{
+				Enabled: true,
+				Store:   LimiterStoreMemory,
+				Memory:  RateLimiterMemoryStore{Rate: 10, Burst: 20, ExpiresIn: time.Minute},
+			},
+		},
+		{
+			name: "zero rate and negative burst",
+			config: &RateLimiter{
+				Enabled: true,
+				Store:   LimiterStoreMemory,
+				Memory:  RateLimiterMemoryStore{Rate: 0, Burst: -1, ExpiresIn: time.Minute},
+			},
+			wantErr: []string{
+				"'rate-limiter-memory-rate' must be greater than 0, got 0",
+				"'rate-limiter-memory-burst' must be at least 0, got -1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Validate() expected error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() error = %v, want to contain %v", err, want)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"github.com/alexferl/golib/config"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/spf13/pflag"
@@ -14,7 +15,7 @@ type Recover struct {
 
 	// Size of the stack to be printed.
 	// Optional. Default value 4KB.
-	StackSize int
+	StackSize int `validate:"min=0"`
 
 	// DisableStackAll disables formatting stack traces of all other goroutines
 	// into buffer after the trace for the current goroutine.
@@ -31,7 +32,8 @@ type Recover struct {
 	DisableErrorHandler bool
 }
 
-// DefaultRecover provides default Recover configuration.
+// DefaultRecover provides default Recover configuration. It is shared by the whole
+// process, use NewRecoverConfig to get a copy that can be changed.
 var DefaultRecover = &Recover{
 	Enabled:             true,
 	StackSize:           4 << 10, // 4 KB
@@ -40,6 +42,17 @@ var DefaultRecover = &Recover{
 	DisableErrorHandler: false,
 }
 
+// NewRecoverConfig returns a copy of DefaultRecover.
+func NewRecoverConfig() *Recover {
+	return DefaultRecover.Clone()
+}
+
+// Clone returns a copy of the configuration.
+func (r *Recover) Clone() *Recover {
+	clone := *r
+	return &clone
+}
+
 const (
 	RecoverEnabled             = "recover-enabled"
 	RecoverStackSize           = "recover-stack-size"
@@ -48,9 +61,18 @@ const (
 	RecoverDisableErrorHandler = "recover-disable-error-handler"
 )
 
+// Validate checks if configuration values are valid. Nothing is checked
+// when the middleware is disabled.
+func (r *Recover) Validate() error {
+	if !r.Enabled {
+		return nil
+	}
+	return config.ValidateComponent(r)
+}
+
 // FlagSet returns a pflag.FlagSet for CLI configuration.
 func (r *Recover) FlagSet() *pflag.FlagSet {
-	fs := pflag.NewFlagSet("Recover", pflag.ExitOnError)
+	fs := pflag.NewFlagSet("Recover", pflag.ContinueOnError)
 
 	fs.BoolVar(&r.Enabled, RecoverEnabled, r.Enabled, "Enable panic recovery middleware")
 	fs.IntVar(&r.StackSize, RecoverStackSize, r.StackSize, "Stack size for recovery in bytes")
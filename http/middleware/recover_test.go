@@ -147,7 +147,7 @@ func TestRecover_FlagSet_DefaultValues(t *testing.T) {
 }
 
 func TestRecover_FlagSet_EnabledByDefault(t *testing.T) {
-	config := DefaultRecover
+	config := NewRecoverConfig()
 
 	fs := config.FlagSet()
 
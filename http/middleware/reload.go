@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/alexferl/golib/config"
+	"github.com/labstack/echo/v4"
+)
+
+// Subscriber notifies changes of the configuration, see
+// config.ConfigLoader.Subscribe.
+type Subscriber interface {
+	Subscribe(fn config.ChangeFunc)
+}
+
+// Option configures a middleware.
+type Option func(*options)
+
+type options struct {
+	subscriber Subscriber
+}
+
+// WithReload rebuilds the middleware from its configuration when a reload of
+// the configuration changes one of its keys, e.g. with a config.ConfigLoader
+// loading with config.WithWatch(true). Requests use a copy of the
+// configuration taken after the reload, never the configuration the reload
+// writes.
+func WithReload(subscriber Subscriber) Option {
+	return func(o *options) {
+		o.subscriber = subscriber
+	}
+}
+
+// reloadable returns a middleware running the middleware returned by build,
+// which builds it from a copy of the configuration. With WithReload, it's
+// rebuilt when a reload changes a key starting with prefix.
+func reloadable(prefix string, build func() echo.MiddlewareFunc, opts []Option) echo.MiddlewareFunc {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var current atomic.Pointer[echo.MiddlewareFunc]
+	mw := build()
+	current.Store(&mw)
+
+	if o.subscriber == nil {
+		return mw
+	}
+
+	o.subscriber.Subscribe(func(changes []config.Change) {
+		for _, change := range changes {
+			if strings.HasPrefix(change.Key, prefix) {
+				mw := build()
+				current.Store(&mw)
+				return
+			}
+		}
+	})
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return (*current.Load())(next)(c)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexferl/golib/config"
+	"github.com/labstack/echo/v4"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestWithReload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.local.toml")
+	writeConfig(t, file, `cors-allow-origins = ["https://a.example.com"]
+rate-limiter-memory-rate = 100
+rate-limiter-memory-burst = 100
+`)
+
+	cors := NewCORSConfig()
+	limiter := NewRateLimiterConfig()
+	loader := config.NewConfigLoader(config.WithConfigPaths(dir)).Register(cors, limiter)
+	if _, err := loader.LoadConfig([]config.LoadOption{config.WithArgs(nil), config.WithConfigFile(true)}); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	e := echo.New()
+	e.Use(NewCORS(cors, WithReload(loader)), NewRateLimiter(limiter, WithReload(loader)))
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	request := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderOrigin, origin)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if got := request("https://a.example.com").Header().Get(echo.HeaderAccessControlAllowOrigin); got != "https://a.example.com" {
+		t.Errorf("allowed origin = %q, want https://a.example.com", got)
+	}
+
+	writeConfig(t, file, `cors-allow-origins = ["https://b.example.com"]
+rate-limiter-memory-rate = 1
+rate-limiter-memory-burst = 1
+`)
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if got := request("https://a.example.com").Header().Get(echo.HeaderAccessControlAllowOrigin); got != "" {
+		t.Errorf("allowed origin = %q, want none after the reload", got)
+	}
+	// the request above took the only token of the reloaded burst
+	if got := request("https://b.example.com"); got.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %v, want %v with the reloaded burst", got.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestWithReload_OtherKeys(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.local.toml")
+	writeConfig(t, file, `app-name = "before"`)
+
+	loader := config.NewConfigLoader(config.WithConfigPaths(dir))
+	if _, err := loader.LoadConfig([]config.LoadOption{config.WithArgs(nil), config.WithConfigFile(true)}); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	built := 0
+	reloadable("cors-", func() echo.MiddlewareFunc {
+		built++
+		return func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+	}, []Option{WithReload(loader)})
+
+	writeConfig(t, file, `app-name = "after"`)
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if built != 1 {
+		t.Errorf("built %d times, want 1 when none of the keys changed", built)
+	}
+}
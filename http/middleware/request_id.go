@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"github.com/alexferl/golib/config"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/spf13/pflag"
@@ -17,20 +18,41 @@ type RequestID struct {
 	TargetHeader string
 }
 
-// DefaultRequestID provides default RequestID configuration.
+// DefaultRequestID provides default RequestID configuration. It is shared by the whole
+// process, use NewRequestIDConfig to get a copy that can be changed.
 var DefaultRequestID = &RequestID{
 	Enabled:      false,
 	TargetHeader: "X-Request-ID",
 }
 
+// NewRequestIDConfig returns a copy of DefaultRequestID.
+func NewRequestIDConfig() *RequestID {
+	return DefaultRequestID.Clone()
+}
+
+// Clone returns a copy of the configuration.
+func (r *RequestID) Clone() *RequestID {
+	clone := *r
+	return &clone
+}
+
 const (
 	RequestIDEnabled      = "request-id-enabled"
 	RequestIDTargetHeader = "request-id-target-header"
 )
 
+// Validate checks if configuration values are valid. Nothing is checked
+// when the middleware is disabled.
+func (r *RequestID) Validate() error {
+	if !r.Enabled {
+		return nil
+	}
+	return config.ValidateComponent(r)
+}
+
 // FlagSet returns a pflag.FlagSet for CLI configuration.
 func (r *RequestID) FlagSet() *pflag.FlagSet {
-	fs := pflag.NewFlagSet("Request ID", pflag.ExitOnError)
+	fs := pflag.NewFlagSet("Request ID", pflag.ContinueOnError)
 
 	fs.BoolVar(&r.Enabled, RequestIDEnabled, r.Enabled, "Enable request ID middleware")
 	fs.StringVar(&r.TargetHeader, RequestIDTargetHeader, r.TargetHeader, "Header name for the request ID")
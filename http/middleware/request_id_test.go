@@ -100,7 +100,7 @@ func TestRequestID_FlagSet_DefaultValues(t *testing.T) {
 }
 
 func TestRequestID_FlagSet_DisabledByDefault(t *testing.T) {
-	config := DefaultRequestID
+	config := NewRequestIDConfig()
 
 	fs := config.FlagSet()
 
@@ -4,6 +4,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/alexferl/golib/config"
 	"github.com/alexferl/golib/logger"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -21,19 +22,40 @@ type RequestLogger struct {
 	Logger *logger.Logger
 }
 
-// DefaultLogger provides default RequestLogger configuration.
+// DefaultLogger provides default RequestLogger configuration. It is shared by the whole
+// process, use NewRequestLoggerConfig to get a copy that can be changed.
 var DefaultLogger = &RequestLogger{
 	Enabled: false,
 	Logger:  nil,
 }
 
+// NewRequestLoggerConfig returns a copy of DefaultLogger.
+func NewRequestLoggerConfig() *RequestLogger {
+	return DefaultLogger.Clone()
+}
+
+// Clone returns a copy of the configuration. The Logger is shared.
+func (l *RequestLogger) Clone() *RequestLogger {
+	clone := *l
+	return &clone
+}
+
 const (
 	RequestLoggerEnabled = "request-logger-enabled"
 )
 
+// Validate checks if configuration values are valid. Nothing is checked
+// when the middleware is disabled.
+func (l *RequestLogger) Validate() error {
+	if !l.Enabled {
+		return nil
+	}
+	return config.ValidateComponent(l)
+}
+
 // FlagSet returns a pflag.FlagSet for CLI configuration.
 func (l *RequestLogger) FlagSet() *pflag.FlagSet {
-	fs := pflag.NewFlagSet("Request Logger", pflag.ExitOnError)
+	fs := pflag.NewFlagSet("Request Logger", pflag.ContinueOnError)
 
 	fs.BoolVar(&l.Enabled, RequestLoggerEnabled, l.Enabled, "Enable request logging middleware")
 
@@ -46,7 +68,7 @@ func NewRequestLogger(config *RequestLogger) echo.MiddlewareFunc {
 	if config.Logger != nil {
 		log = config.Logger
 	} else {
-		defaultLogger, err := logger.New(logger.DefaultConfig)
+		defaultLogger, err := logger.New(nil)
 		if err != nil {
 			panic(err)
 		}
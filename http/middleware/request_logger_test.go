@@ -81,7 +81,7 @@ func TestRequestLogger_FlagSet_DefaultValues(t *testing.T) {
 }
 
 func TestRequestLogger_FlagSet_DisabledByDefault(t *testing.T) {
-	config := DefaultLogger
+	config := NewRequestLoggerConfig()
 
 	fs := config.FlagSet()
 
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	secure "github.com/alexferl/echo-secure"
+	"github.com/alexferl/golib/config"
 	"github.com/labstack/echo/v4"
 	"github.com/spf13/pflag"
 )
@@ -10,7 +11,7 @@ import (
 type StrictTransportSecurity struct {
 	// MaxAge specifies the max age for HSTS in seconds.
 	// Optional. Default value from secure.DefaultConfig.
-	MaxAge int
+	MaxAge int `validate:"min=0"`
 
 	// ExcludeSubdomains indicates whether to exclude subdomains from HSTS.
 	// Optional. Default value from secure.DefaultConfig.
@@ -65,14 +66,15 @@ type Secure struct {
 
 	// XContentTypeOptions specifies the X-Content-Type-Options header value.
 	// Optional. Default value from secure.DefaultConfig.
-	XContentTypeOptions string
+	XContentTypeOptions string `validate:"oneof=nosniff"`
 
 	// XFrameOptions specifies the X-Frame-Options header value.
 	// Optional. Default value from secure.DefaultConfig.
-	XFrameOptions string
+	XFrameOptions string `validate:"oneof=DENY SAMEORIGIN"`
 }
 
-// DefaultSecure provides default Secure configuration.
+// DefaultSecure provides default Secure configuration. It is shared by the whole
+// process, use NewSecureConfig to get a copy that can be changed.
 var DefaultSecure = &Secure{
 	Enabled:                         false,
 	ContentSecurityPolicy:           secure.DefaultConfig.ContentSecurityPolicy,
@@ -92,6 +94,17 @@ var DefaultSecure = &Secure{
 	XFrameOptions:       secure.DefaultConfig.XFrameOptions,
 }
 
+// NewSecureConfig returns a copy of DefaultSecure.
+func NewSecureConfig() *Secure {
+	return DefaultSecure.Clone()
+}
+
+// Clone returns a copy of the configuration.
+func (s *Secure) Clone() *Secure {
+	clone := *s
+	return &clone
+}
+
 const (
 	SecureEnabled                         = "secure-enabled"
 	SecureContentSecurityPolicy           = "secure-content-security-policy"
@@ -109,9 +122,18 @@ const (
 	SecureXFrameOptions                   = "secure-x-frame-options"
 )
 
+// Validate checks if configuration values are valid. Nothing is checked
+// when the middleware is disabled.
+func (s *Secure) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+	return config.ValidateComponent(s)
+}
+
 // FlagSet returns a pflag.FlagSet for CLI configuration.
 func (s *Secure) FlagSet() *pflag.FlagSet {
-	fs := pflag.NewFlagSet("Secure", pflag.ExitOnError)
+	fs := pflag.NewFlagSet("Secure", pflag.ContinueOnError)
 
 	fs.BoolVar(&s.Enabled, SecureEnabled, s.Enabled, "Enable security middleware")
 	fs.StringVar(&s.ContentSecurityPolicy, SecureContentSecurityPolicy, s.ContentSecurityPolicy, "Content Security Policy header value")
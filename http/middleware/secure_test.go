@@ -207,7 +207,7 @@ func TestSecure_FlagSet_DefaultValues(t *testing.T) {
 }
 
 func TestSecure_FlagSet_DisabledByDefault(t *testing.T) {
-	config := DefaultSecure
+	config := NewSecureConfig()
 
 	fs := config.FlagSet()
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/alexferl/golib/config"
 	"github.com/gorilla/sessions"
 	"github.com/labstack/echo-contrib/session"
 	"github.com/labstack/echo/v4"
@@ -13,8 +14,9 @@ import (
 // SessionCookieStore holds configuration for cookie-based session storage.
 type SessionCookieStore struct {
 	// Secret specifies the secret key for cookie sessions.
-	// Optional. Default value "changeme".
-	Secret string
+	// Optional. Default value "changeme", which is rejected in the "prod"
+	// environment.
+	Secret string `validate:"required"`
 }
 
 type SessionStore string
@@ -67,7 +69,8 @@ type Session struct {
 	Cookie SessionCookieStore
 }
 
-// DefaultSession provides default Session configuration.
+// DefaultSession provides default Session configuration. It is shared by the whole
+// process, use NewSessionConfig to get a copy that can be changed.
 var DefaultSession = &Session{
 	Enabled: false,
 	Store:   sessionStoreCookie,
@@ -76,15 +79,47 @@ var DefaultSession = &Session{
 	},
 }
 
+// NewSessionConfig returns a copy of DefaultSession.
+func NewSessionConfig() *Session {
+	return DefaultSession.Clone()
+}
+
+// Clone returns a copy of the configuration.
+func (s *Session) Clone() *Session {
+	clone := *s
+	return &clone
+}
+
 const (
 	SessionEnabled      = "session-enabled"
 	SessionStoreType    = "session-store" // Changed from SessionStore
 	SessionCookieSecret = "session-cookie-secret"
 )
 
+// Validate checks if configuration values are valid. Nothing is checked
+// when the middleware is disabled.
+func (s *Session) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+	return config.ValidateComponent(s)
+}
+
+// Constraints returns the constraints checked by config.ConfigLoader: the
+// default cookie secret can't be used in the "prod" environment.
+func (s *Session) Constraints() []config.Constraint {
+	return []config.Constraint{
+		{
+			Key:  SessionCookieSecret,
+			Rule: "ne=changeme",
+			When: []config.Condition{config.If(SessionEnabled, "true"), config.If(config.EnvName, "prod")},
+		},
+	}
+}
+
 // FlagSet returns a pflag.FlagSet for CLI configuration.
 func (s *Session) FlagSet() *pflag.FlagSet {
-	fs := pflag.NewFlagSet("Session", pflag.ExitOnError)
+	fs := pflag.NewFlagSet("Session", pflag.ContinueOnError)
 
 	fs.BoolVar(&s.Enabled, SessionEnabled, s.Enabled, "Enable session middleware")
 	fs.Var(&s.Store, SessionStoreType, fmt.Sprintf("Session store type\nValues: %s", strings.Join(SessionStores, ", ")))
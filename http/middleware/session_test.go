@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/alexferl/golib/config"
 )
 
 func TestSession_FlagSet(t *testing.T) {
@@ -133,7 +136,7 @@ func TestSession_FlagSet_DefaultValues(t *testing.T) {
 }
 
 func TestSession_FlagSet_DisabledByDefault(t *testing.T) {
-	config := DefaultSession
+	config := NewSessionConfig()
 
 	fs := config.FlagSet()
 
@@ -235,3 +238,35 @@ func TestNewSession_InvalidStore(t *testing.T) {
 		t.Error("NewSession() with invalid store should return nil")
 	}
 }
+
+func TestSession_Constraints(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "default secret in dev", args: []string{"--env-name", "dev", "--session-enabled"}},
+		{name: "default secret in prod when disabled", args: []string{"--env-name", "prod"}},
+		{name: "default secret in prod", args: []string{"--env-name", "prod", "--session-enabled"}, wantErr: true},
+		{name: "secret in prod", args: []string{"--env-name", "prod", "--session-enabled", "--session-cookie-secret", "s3cr3t"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := config.NewConfigLoader().Register(NewSessionConfig())
+			_, err := loader.LoadConfig([]config.LoadOption{config.WithArgs(tt.args)})
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("LoadConfig() error = %v", err)
+				}
+				return
+			}
+
+			want := "Session: 'session-cookie-secret' must not be 'changeme' when 'session-enabled' is 'true' and 'env-name' is 'prod'"
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("LoadConfig() error = %v, want %v", err, want)
+			}
+		})
+	}
+}
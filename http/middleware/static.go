@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"github.com/alexferl/golib/config"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/spf13/pflag"
@@ -14,7 +15,7 @@ type Static struct {
 
 	// Root specifies the root directory for static files.
 	// Optional. Default value "".
-	Root string
+	Root string `validate:"required,dir"`
 
 	// Index specifies the index file name.
 	// Optional. Default value "index.html".
@@ -33,7 +34,8 @@ type Static struct {
 	IgnoreBase bool
 }
 
-// DefaultStatic provides default Static configuration.
+// DefaultStatic provides default Static configuration. It is shared by the whole
+// process, use NewStaticConfig to get a copy that can be changed.
 var DefaultStatic = &Static{
 	Enabled:    false,
 	Root:       "",
@@ -43,6 +45,17 @@ var DefaultStatic = &Static{
 	IgnoreBase: false,
 }
 
+// NewStaticConfig returns a copy of DefaultStatic.
+func NewStaticConfig() *Static {
+	return DefaultStatic.Clone()
+}
+
+// Clone returns a copy of the configuration.
+func (s *Static) Clone() *Static {
+	clone := *s
+	return &clone
+}
+
 const (
 	StaticEnabled    = "static-enabled"
 	StaticRoot       = "static-root"
@@ -52,9 +65,18 @@ const (
 	StaticIgnoreBase = "static-ignore-base"
 )
 
+// Validate checks if configuration values are valid. Nothing is checked
+// when the middleware is disabled.
+func (s *Static) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+	return config.ValidateComponent(s)
+}
+
 // FlagSet returns a pflag.FlagSet for CLI configuration.
 func (s *Static) FlagSet() *pflag.FlagSet {
-	fs := pflag.NewFlagSet("Static", pflag.ExitOnError)
+	fs := pflag.NewFlagSet("Static", pflag.ContinueOnError)
 
 	fs.BoolVar(&s.Enabled, StaticEnabled, s.Enabled, "Enable static file serving middleware")
 	fs.StringVar(&s.Root, StaticRoot, s.Root, "Root directory for static files")
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -157,7 +158,7 @@ func TestStatic_FlagSet_DefaultValues(t *testing.T) {
 }
 
 func TestStatic_FlagSet_DisabledByDefault(t *testing.T) {
-	config := DefaultStatic
+	config := NewStaticConfig()
 
 	fs := config.FlagSet()
 
@@ -195,3 +196,45 @@ func TestNewStatic_DefaultConfig(t *testing.T) {
 		t.Fatal("NewStatic() with DefaultStatic returned nil")
 	}
 }
+
+func TestStatic_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *Static
+		wantErr string
+	}{
+		{
+			name:   "disabled without root",
+			config: &Static{Enabled: false},
+		},
+		{
+			name:   "existing root",
+			config: &Static{Enabled: true, Root: t.TempDir()},
+		},
+		{
+			name:    "missing root",
+			config:  &Static{Enabled: true},
+			wantErr: "'static-root' is required",
+		},
+		{
+			name:    "nonexistent root",
+			config:  &Static{Enabled: true, Root: "/nonexistent/public"},
+			wantErr: "'static-root' must be an existing directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want to contain %v", err, tt.wantErr)
+			}
+		})
+	}
+}
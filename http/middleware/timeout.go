@@ -3,6 +3,7 @@ package middleware
 import (
 	"time"
 
+	"github.com/alexferl/golib/config"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/spf13/pflag"
@@ -20,25 +21,46 @@ type Timeout struct {
 
 	// Duration specifies the timeout duration.
 	// Optional. Default value 15 seconds.
-	Duration time.Duration
+	Duration time.Duration `validate:"min=0s"`
 }
 
-// DefaultTimeout provides default Timeout configuration.
+// DefaultTimeout provides default Timeout configuration. It is shared by the whole
+// process, use NewTimeoutConfig to get a copy that can be changed.
 var DefaultTimeout = &Timeout{
 	Enabled:      true,
 	ErrorMessage: "Request timeout",
 	Duration:     15 * time.Second,
 }
 
+// NewTimeoutConfig returns a copy of DefaultTimeout.
+func NewTimeoutConfig() *Timeout {
+	return DefaultTimeout.Clone()
+}
+
+// Clone returns a copy of the configuration.
+func (t *Timeout) Clone() *Timeout {
+	clone := *t
+	return &clone
+}
+
 const (
 	TimeoutEnabled      = "timeout-enabled"
 	TimeoutErrorMessage = "timeout-error-message"
 	TimeoutDuration     = "timeout-duration"
 )
 
+// Validate checks if configuration values are valid. Nothing is checked
+// when the middleware is disabled.
+func (t *Timeout) Validate() error {
+	if !t.Enabled {
+		return nil
+	}
+	return config.ValidateComponent(t)
+}
+
 // FlagSet returns a pflag.FlagSet for CLI configuration.
 func (t *Timeout) FlagSet() *pflag.FlagSet {
-	fs := pflag.NewFlagSet("Timeout", pflag.ExitOnError)
+	fs := pflag.NewFlagSet("Timeout", pflag.ContinueOnError)
 
 	fs.BoolVar(&t.Enabled, TimeoutEnabled, t.Enabled, "Enable request timeout middleware")
 	fs.StringVar(&t.ErrorMessage, TimeoutErrorMessage, t.ErrorMessage, "Error message for timeout responses")
@@ -128,7 +128,7 @@ func TestTimeout_FlagSet_DefaultValues(t *testing.T) {
 }
 
 func TestTimeout_FlagSet_EnabledByDefault(t *testing.T) {
-	config := DefaultTimeout
+	config := NewTimeoutConfig()
 
 	fs := config.FlagSet()
 
@@ -1,9 +1,13 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
+	"os"
+	"slices"
 	"time"
 
+	"github.com/alexferl/golib/config"
 	"github.com/labstack/echo/v4"
 	"github.com/spf13/pflag"
 )
@@ -12,7 +16,7 @@ import (
 type Config struct {
 	// Name specifies the application name.
 	// Optional. Default value "app".
-	Name string
+	Name string `validate:"required"`
 
 	// Version specifies the application version.
 	// Optional. Default value "1.0.0".
@@ -20,7 +24,7 @@ type Config struct {
 
 	// GracefulTimeout specifies the duration for graceful shutdown.
 	// Optional. Default value 30 seconds.
-	GracefulTimeout time.Duration
+	GracefulTimeout time.Duration `validate:"min=0s"`
 
 	// HTTP holds HTTP server configuration.
 	// Optional. Default value with localhost:8080 bind address.
@@ -51,27 +55,27 @@ type Config struct {
 type HTTPConfig struct {
 	// BindAddr specifies the HTTP bind address.
 	// Optional. Default value "localhost:8080".
-	BindAddr string
+	BindAddr string `validate:"required,hostport"`
 
 	// IdleTimeout specifies the HTTP idle timeout.
 	// Optional. Default value 60 seconds.
-	IdleTimeout time.Duration
+	IdleTimeout time.Duration `validate:"min=0s"`
 
 	// ReadTimeout specifies the HTTP read timeout.
 	// Optional. Default value 10 seconds.
-	ReadTimeout time.Duration
+	ReadTimeout time.Duration `validate:"min=0s"`
 
 	// ReadHeaderTimeout specifies the HTTP read header timeout.
 	// Optional. Default value 5 seconds.
-	ReadHeaderTimeout time.Duration
+	ReadHeaderTimeout time.Duration `validate:"min=0s"`
 
 	// WriteTimeout specifies the HTTP write timeout.
 	// Optional. Default value 10 seconds.
-	WriteTimeout time.Duration
+	WriteTimeout time.Duration `validate:"min=0s"`
 
 	// MaxHeaderBytes specifies the maximum header bytes.
 	// Optional. Default value 1MB.
-	MaxHeaderBytes int
+	MaxHeaderBytes int `validate:"min=0"`
 }
 
 // TLSConfig holds TLS/HTTPS server configuration.
@@ -82,15 +86,15 @@ type TLSConfig struct {
 
 	// BindAddr specifies the TLS bind address.
 	// Optional. Default value "localhost:8443".
-	BindAddr string
+	BindAddr string `validate:"hostport"`
 
 	// CertFile specifies the TLS certificate file path.
-	// Optional. Default value "".
-	CertFile string
+	// Required when TLS is enabled and ACME is not. Default value "".
+	CertFile string `validate:"tls-file"`
 
 	// KeyFile specifies the TLS key file path.
-	// Optional. Default value "".
-	KeyFile string
+	// Required when TLS is enabled and ACME is not. Default value "".
+	KeyFile string `validate:"tls-file"`
 
 	// ACME holds ACME/Let's Encrypt configuration.
 	// Optional. Default value with ACME disabled.
@@ -108,7 +112,7 @@ type ACMEConfig struct {
 	Email string
 
 	// HostWhitelist specifies the ACME host whitelist.
-	// Optional. Default value empty slice.
+	// Required when TLS and ACME are enabled. Default value empty slice.
 	HostWhitelist []string
 
 	// CachePath specifies the ACME cache path.
@@ -117,7 +121,7 @@ type ACMEConfig struct {
 
 	// DirectoryURL specifies the ACME directory URL.
 	// Optional. Default value "".
-	DirectoryURL string
+	DirectoryURL string `validate:"url"`
 }
 
 // CompressConfig holds compression configuration.
@@ -128,11 +132,11 @@ type CompressConfig struct {
 
 	// Level specifies the compression level.
 	// Optional. Default value 6.
-	Level int
+	Level int `validate:"min=-3,max=9"`
 
 	// MinLength specifies the minimum length for compression.
 	// Optional. Default value 1024.
-	MinLength int
+	MinLength int `validate:"min=0"`
 }
 
 // RedirectConfig holds redirect configuration.
@@ -143,14 +147,14 @@ type RedirectConfig struct {
 
 	// Code specifies the redirect status code.
 	// Optional. Default value 301 (Moved Permanently).
-	Code int
+	Code int `validate:"oneof=301 302 303 307 308"`
 }
 
 // HealthcheckConfig holds healthcheck endpoint configuration.
 type HealthcheckConfig struct {
 	// LivenessEndpoint specifies the liveness check endpoint.
 	// Optional. Default value "/livez".
-	LivenessEndpoint string
+	LivenessEndpoint string `validate:"required"`
 
 	// LivenessHandler specifies the liveness check handler.
 	// Optional. Default value returns 200 OK.
@@ -158,7 +162,7 @@ type HealthcheckConfig struct {
 
 	// ReadinessEndpoint specifies the readiness check endpoint.
 	// Optional. Default value "/readyz".
-	ReadinessEndpoint string
+	ReadinessEndpoint string `validate:"required"`
 
 	// ReadinessHandler specifies the readiness check handler.
 	// Optional. Default value returns 200 OK.
@@ -166,7 +170,7 @@ type HealthcheckConfig struct {
 
 	// StartupEndpoint specifies the startup check endpoint.
 	// Optional. Default value "/startupz".
-	StartupEndpoint string
+	StartupEndpoint string `validate:"required"`
 
 	// StartupHandler specifies the startup check handler.
 	// Optional. Default value returns 200 OK.
@@ -185,10 +189,11 @@ type PrometheusConfig struct {
 
 	// Path specifies the HTTP path for Prometheus metrics.
 	// Optional. Default value "/metrics".
-	Path string
+	Path string `validate:"required"`
 }
 
-// DefaultConfig provides default server configuration.
+// DefaultConfig provides default server configuration. It is shared by the
+// whole process, use NewConfig to get a copy that can be changed.
 var DefaultConfig = &Config{
 	Name:            "app",
 	Version:         "1.0.0",
@@ -237,6 +242,19 @@ var DefaultConfig = &Config{
 	},
 }
 
+// NewConfig returns a copy of DefaultConfig.
+func NewConfig() *Config {
+	return DefaultConfig.Clone()
+}
+
+// Clone returns a deep copy of the configuration, so changing one doesn't
+// change the other, e.g. to run an admin server next to the public one.
+func (c *Config) Clone() *Config {
+	clone := *c
+	clone.TLS.ACME.HostWhitelist = slices.Clone(c.TLS.ACME.HostWhitelist)
+	return &clone
+}
+
 const (
 	ServerName                         = "server-name"
 	ServerVersion                      = "server-version"
@@ -268,9 +286,42 @@ const (
 	ServerPrometheusPath               = "server-prometheus-path"
 )
 
+// Validate checks if configuration values are valid.
+func (c *Config) Validate() error {
+	return config.ValidateComponent(c, config.WithRule("tls-file", c.validateTLSFile))
+}
+
+// validateTLSFile checks that value is an existing file when the certificate
+// and key files are used, i.e. when TLS is enabled without ACME.
+func (c *Config) validateTLSFile(value any, _ string) error {
+	if !c.TLS.Enabled || c.TLS.ACME.Enabled {
+		return nil
+	}
+
+	path := fmt.Sprint(value)
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return fmt.Errorf("must be an existing file, got '%s'", path)
+	}
+	return nil
+}
+
+// Constraints returns the keys required depending on other keys: the
+// certificate and key files when TLS is enabled without ACME, and the ACME
+// host whitelist when ACME is.
+func (c *Config) Constraints() []config.Constraint {
+	manualTLS := []config.Condition{config.If(ServerTLSEnabled, "true"), config.Unless(ServerTLSACMEEnabled, "true")}
+	acme := []config.Condition{config.If(ServerTLSEnabled, "true"), config.If(ServerTLSACMEEnabled, "true")}
+
+	return []config.Constraint{
+		{Key: ServerTLSCertFile, Rule: "required", When: manualTLS},
+		{Key: ServerTLSKeyFile, Rule: "required", When: manualTLS},
+		{Key: ServerTLSACMEHostWhitelist, Rule: "required", When: acme},
+	}
+}
+
 // FlagSet returns a pflag.FlagSet for CLI configuration.
 func (c *Config) FlagSet() *pflag.FlagSet {
-	fs := pflag.NewFlagSet("Server", pflag.ExitOnError)
+	fs := pflag.NewFlagSet("Server", pflag.ContinueOnError)
 
 	fs.StringVar(&c.Name, ServerName, c.Name, "Application name")
 	fs.StringVar(&c.Version, ServerVersion, c.Version, "Application version")
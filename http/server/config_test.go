@@ -2,8 +2,13 @@ package server
 
 import (
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/alexferl/golib/config"
 )
 
 func TestConfig_FlagSet(t *testing.T) {
@@ -392,6 +397,44 @@ func TestDefaultConfig(t *testing.T) {
 	}
 }
 
+func TestNewConfig(t *testing.T) {
+	public := NewConfig()
+	admin := NewConfig()
+	if public == DefaultConfig || public == admin {
+		t.Fatal("NewConfig() returned a shared instance instead of a copy")
+	}
+
+	err := admin.FlagSet().Parse([]string{
+		"--server-http-bind-addr", "localhost:9090",
+		"--server-tls-acme-host-whitelist", "admin.example.com",
+	})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	admin.TLS.ACME.HostWhitelist[0] = "changed.example.com"
+
+	for _, config := range []*Config{public, DefaultConfig} {
+		if config.HTTP.BindAddr != "localhost:8080" {
+			t.Errorf("HTTP.BindAddr = %v, want localhost:8080", config.HTTP.BindAddr)
+		}
+		if len(config.TLS.ACME.HostWhitelist) != 0 {
+			t.Errorf("TLS.ACME.HostWhitelist = %v, want empty", config.TLS.ACME.HostWhitelist)
+		}
+	}
+}
+
+func TestConfig_Clone(t *testing.T) {
+	config := NewConfig()
+	config.TLS.ACME.HostWhitelist = []string{"example.com"}
+
+	clone := config.Clone()
+	clone.TLS.ACME.HostWhitelist[0] = "changed.example.com"
+
+	if config.TLS.ACME.HostWhitelist[0] != "example.com" {
+		t.Errorf("TLS.ACME.HostWhitelist = %v, want [example.com]", config.TLS.ACME.HostWhitelist)
+	}
+}
+
 func TestConfig_FlagSet_DefaultValues(t *testing.T) {
 	config := &Config{
 		Name:            "customapp",
@@ -505,3 +548,155 @@ func TestConfig_FlagSet_EmptyParse(t *testing.T) {
 		t.Errorf("Prometheus.Path = %v, want /metrics (default)", config.Prometheus.Path)
 	}
 }
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr []string
+	}{
+		{
+			name:   "default config",
+			modify: func(c *Config) {},
+		},
+		{
+			name:    "invalid bind address",
+			modify:  func(c *Config) { c.HTTP.BindAddr = "localhost" },
+			wantErr: []string{"'server-http-bind-addr' must be a host:port address"},
+		},
+		{
+			name:    "compression level out of range",
+			modify:  func(c *Config) { c.Compress.Level = 12 },
+			wantErr: []string{"'server-compress-level' must be at most 9, got 12"},
+		},
+		{
+			name:    "invalid redirect code",
+			modify:  func(c *Config) { c.Redirect.Code = http.StatusOK },
+			wantErr: []string{"'server-redirect-code' must be one of: 301, 302, 303, 307, 308, got '200'"},
+		},
+		{
+			name: "missing cert file",
+			modify: func(c *Config) {
+				c.TLS.Enabled = true
+				c.TLS.CertFile = "/nonexistent/cert.pem"
+			},
+			wantErr: []string{"'server-tls-cert-file' must be an existing file"},
+		},
+		{
+			name: "stale cert and key files with tls disabled",
+			modify: func(c *Config) {
+				c.TLS.CertFile = "/nonexistent/cert.pem"
+				c.TLS.KeyFile = "/nonexistent/key.pem"
+			},
+		},
+		{
+			name: "stale cert and key files with acme",
+			modify: func(c *Config) {
+				c.TLS.Enabled = true
+				c.TLS.ACME.Enabled = true
+				c.TLS.CertFile = "/nonexistent/cert.pem"
+				c.TLS.KeyFile = "/nonexistent/key.pem"
+			},
+		},
+		{
+			name: "all violations",
+			modify: func(c *Config) {
+				c.Name = ""
+				c.HTTP.ReadTimeout = -time.Second
+				c.TLS.ACME.DirectoryURL = "acme"
+				c.Prometheus.Path = ""
+			},
+			wantErr: []string{
+				"'server-name' is required",
+				"'server-http-read-timeout' must be at least 0s, got -1s",
+				"'server-tls-acme-directory-url' must be an absolute URL",
+				"'server-prometheus-path' is required",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := *DefaultConfig
+			tt.modify(&config)
+
+			err := config.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("Validate() expected error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() error = %v, want to contain %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_Constraints(t *testing.T) {
+	certFile := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(certFile, []byte("cert"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr []string
+	}{
+		{
+			name: "tls disabled",
+		},
+		{
+			name: "tls without cert and key",
+			args: []string{"--server-tls-enabled"},
+			wantErr: []string{
+				"Server: 'server-tls-cert-file' is required when 'server-tls-enabled' is 'true' and 'server-tls-acme-enabled' is not 'true'",
+				"Server: 'server-tls-key-file' is required when 'server-tls-enabled' is 'true' and 'server-tls-acme-enabled' is not 'true'",
+			},
+		},
+		{
+			name: "tls with cert and key",
+			args: []string{"--server-tls-enabled", "--server-tls-cert-file", certFile, "--server-tls-key-file", certFile},
+		},
+		{
+			name:    "acme without host whitelist",
+			args:    []string{"--server-tls-enabled", "--server-tls-acme-enabled"},
+			wantErr: []string{"Server: 'server-tls-acme-host-whitelist' is required when 'server-tls-enabled' is 'true' and 'server-tls-acme-enabled' is 'true'"},
+		},
+		{
+			name: "acme with host whitelist",
+			args: []string{"--server-tls-enabled", "--server-tls-acme-enabled", "--server-tls-acme-host-whitelist", "example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := config.NewConfigLoader().Register(NewConfig())
+			_, err := loader.LoadConfig([]config.LoadOption{config.WithArgs(tt.args)})
+
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("LoadConfig() error = %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("LoadConfig() expected error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("LoadConfig() error = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+}
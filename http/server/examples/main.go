@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
@@ -12,7 +14,6 @@ import (
 	"github.com/alexferl/golib/http/server"
 	"github.com/alexferl/golib/logger"
 	"github.com/labstack/echo/v4"
-	"github.com/spf13/pflag"
 )
 
 type AppConfig struct {
@@ -21,29 +22,44 @@ type AppConfig struct {
 	Logger        *logger.Config
 	RequestID     *middleware.RequestID
 	RequestLogger *middleware.RequestLogger
+	CORS          *middleware.CORS
+	RateLimiter   *middleware.RateLimiter
 }
 
 func main() {
 	appConfig := &AppConfig{
 		Config:        config.Config{AppName: "myapp", EnvName: "local"},
-		Server:        server.DefaultConfig,
-		Logger:        logger.DefaultConfig,
-		RequestID:     middleware.DefaultRequestID,
-		RequestLogger: middleware.DefaultLogger,
+		Server:        server.NewConfig(),
+		Logger:        logger.NewConfig(),
+		RequestID:     middleware.NewRequestIDConfig(),
+		RequestLogger: middleware.NewRequestLoggerConfig(),
+		CORS:          middleware.NewCORSConfig(),
+		RateLimiter:   middleware.NewRateLimiterConfig(),
 	}
 
-	configLoader := config.NewConfigLoader()
-
-	_, err := configLoader.LoadConfig(
-		[]config.LoadOption{},
-		func(fs *pflag.FlagSet) {
-			fs.AddFlagSet(appConfig.Server.FlagSet())
-			fs.AddFlagSet(appConfig.Logger.FlagSet())
-			fs.AddFlagSet(appConfig.RequestID.FlagSet())
-			fs.AddFlagSet(appConfig.RequestLogger.FlagSet())
-		},
+	configLoader := config.NewConfigLoader().Register(
+		appConfig.Server,
+		appConfig.Logger,
+		appConfig.RequestID,
+		appConfig.RequestLogger,
+		appConfig.CORS,
+		appConfig.RateLimiter,
 	)
+
+	// reload the environment and the providers on SIGHUP
+	_, err := configLoader.LoadConfig([]config.LoadOption{
+		config.WithValidateConfig(true),
+		config.WithWatch(true),
+	})
 	if err != nil {
+		var helpErr *config.HelpError
+		switch {
+		case errors.As(err, &helpErr):
+			fmt.Print(helpErr.Usage)
+			os.Exit(0)
+		case errors.Is(err, config.ErrConfigValid):
+			os.Exit(0)
+		}
 		log.Fatal("failed to load config:", err)
 	}
 
@@ -52,6 +68,22 @@ func main() {
 		log.Fatal("failed to create logger:", err)
 	}
 
+	for _, d := range configLoader.Deprecations() {
+		appLogger.LogDeprecation(d)
+	}
+
+	configLoader.Subscribe(func(changes []config.Change) {
+		if err := appLogger.Reload(); err != nil {
+			appLogger.Error().Err(err).Msg("failed to reload logger")
+		}
+		for _, change := range changes {
+			appLogger.Info().Str("key", change.Key).Msg("configuration changed")
+		}
+	})
+	configLoader.OnReloadError(func(err error) {
+		appLogger.Error().Err(err).Msg("failed to reload config")
+	})
+
 	appConfig.RequestLogger.Logger = appLogger
 
 	var middlewares []echo.MiddlewareFunc
@@ -63,6 +95,17 @@ func main() {
 		middlewares = append(middlewares, middleware.NewRequestLogger(appConfig.RequestLogger))
 	}
 
+	// the CORS and rate limiter settings follow the reloaded configuration
+	if appConfig.CORS.Enabled {
+		middlewares = append(middlewares, middleware.NewCORS(appConfig.CORS, middleware.WithReload(configLoader)))
+	}
+
+	if appConfig.RateLimiter.Enabled {
+		if mw := middleware.NewRateLimiter(appConfig.RateLimiter, middleware.WithReload(configLoader)); mw != nil {
+			middlewares = append(middlewares, mw)
+		}
+	}
+
 	srv := server.New(*appConfig.Server,
 		server.WithLogger(appLogger),
 		server.WithMiddleware(middlewares...),
@@ -91,6 +134,10 @@ func main() {
 	case <-quit:
 		srv.Logger().Info().Msg("received shutdown signal")
 
+		if err := configLoader.Close(); err != nil {
+			srv.Logger().Error().Err(err).Msg("failed to stop watching config")
+		}
+
 		ctx, cancel := context.WithTimeout(context.Background(), appConfig.Server.GracefulTimeout)
 		defer cancel()
 
@@ -62,7 +62,7 @@ func New(config Config, options ...Option) *Server {
 	e.HidePort = true
 
 	server := &Server{
-		config: config,
+		config: *config.Clone(),
 		echo:   e,
 		errCh:  make(chan error, 10),
 		ctx:    ctx,
@@ -74,7 +74,7 @@ func New(config Config, options ...Option) *Server {
 	}
 
 	if server.logger == nil {
-		defaultLogger, err := logger.New(logger.DefaultConfig)
+		defaultLogger, err := logger.New(nil)
 		if err != nil {
 			panic(err)
 		}
@@ -227,7 +227,7 @@ func TestCreateHTTPServer(t *testing.T) {
 }
 
 func TestHealthcheckEndpoints(t *testing.T) {
-	config := *DefaultConfig
+	config := *NewConfig()
 	server := New(config)
 
 	req := httptest.NewRequest("GET", config.Healthcheck.LivenessEndpoint, nil)
@@ -292,7 +292,7 @@ func TestDefaultHealthcheckHandler(t *testing.T) {
 }
 
 func TestHealthcheckEndpointsRegistered(t *testing.T) {
-	config := *DefaultConfig
+	config := *NewConfig()
 	server := New(config)
 
 	e := server.Echo()
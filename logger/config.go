@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
@@ -15,46 +16,59 @@ type Config struct {
 	LogOutput string
 }
 
-// DefaultConfig provides sensible default values.
+// DefaultConfig provides sensible default values. It is shared by the whole
+// process, use NewConfig to get a copy that can be changed.
 var DefaultConfig = &Config{
 	LogLevel:  LevelInfo,
 	LogFormat: FormatText,
 	LogOutput: OutputStdOut,
 }
 
+// NewConfig returns a copy of DefaultConfig.
+func NewConfig() *Config {
+	return DefaultConfig.Clone()
+}
+
+// Clone returns a copy of the configuration.
+func (c *Config) Clone() *Config {
+	clone := *c
+	return &clone
+}
+
 const (
 	LogLevel  = "log-level"
 	LogFormat = "log-format"
 	LogOutput = "log-output"
 )
 
-// Validate checks if configuration values are valid.
+// Validate checks if configuration values are valid. It returns every
+// violation at once, worded like config.ValidateStruct words them. Unlike the
+// other golib configs, it doesn't use `validate` tags: the logger is a leaf
+// module, which would otherwise depend on the config module and its
+// dependencies for three oneof checks.
 func (c *Config) Validate() error {
-	logLevel := strings.ToUpper(c.LogLevel)
-	logFormat := strings.ToLower(c.LogFormat)
-	logOutput := strings.ToLower(c.LogOutput)
-
-	if !slices.Contains(levels, logLevel) {
-		return fmt.Errorf("invalid log level '%s', must be one of: %s",
-			c.LogLevel, strings.Join(levels, ", "))
-	}
+	return errors.Join(
+		validateOneOf(LogLevel, strings.ToUpper(c.LogLevel), c.LogLevel, levels),
+		validateOneOf(LogFormat, strings.ToLower(c.LogFormat), c.LogFormat, formats),
+		validateOneOf(LogOutput, strings.ToLower(c.LogOutput), c.LogOutput, outputs),
+	)
+}
 
-	if !slices.Contains(formats, logFormat) {
-		return fmt.Errorf("invalid log format '%s', must be one of: %s",
-			c.LogFormat, strings.Join(formats, ", "))
+// validateOneOf checks that the value of the flag name, normalized to match
+// the case of values, is one of values.
+func validateOneOf(name, normalized, value string, values []string) error {
+	if value == "" {
+		return fmt.Errorf("'%s' is required", name)
 	}
-
-	if !slices.Contains(outputs, logOutput) {
-		return fmt.Errorf("invalid log output '%s', must be one of: %s",
-			c.LogOutput, strings.Join(outputs, ", "))
+	if !slices.Contains(values, normalized) {
+		return fmt.Errorf("'%s' must be one of: %s, got '%s'", name, strings.Join(values, ", "), value)
 	}
-
 	return nil
 }
 
 // FlagSet returns a pflag.FlagSet for CLI configuration.
 func (c *Config) FlagSet() *pflag.FlagSet {
-	fs := pflag.NewFlagSet("Logger", pflag.ExitOnError)
+	fs := pflag.NewFlagSet("Logger", pflag.ContinueOnError)
 	fs.StringVar(&c.LogLevel, LogLevel, c.LogLevel,
 		fmt.Sprintf("Log granularity\nValues: %s", strings.Join(levels, ", ")),
 	)
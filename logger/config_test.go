@@ -38,7 +38,7 @@ func TestConfig_Validate(t *testing.T) {
 				LogOutput: "stdout",
 			},
 			wantErr: true,
-			errMsg:  "invalid log level 'INVALID'",
+			errMsg:  "'log-level' must be one of: PANIC, FATAL, ERROR, WARN, INFO, DEBUG, TRACE, DISABLED, got 'INVALID'",
 		},
 		{
 			name: "invalid log format",
@@ -48,7 +48,7 @@ func TestConfig_Validate(t *testing.T) {
 				LogOutput: "stdout",
 			},
 			wantErr: true,
-			errMsg:  "invalid log format 'xml'",
+			errMsg:  "'log-format' must be one of: text, json, got 'xml'",
 		},
 		{
 			name: "invalid log output",
@@ -58,7 +58,7 @@ func TestConfig_Validate(t *testing.T) {
 				LogOutput: "file",
 			},
 			wantErr: true,
-			errMsg:  "invalid log output 'file'",
+			errMsg:  "'log-output' must be one of: stdout, stderr, got 'file'",
 		},
 		{
 			name: "empty values",
@@ -68,7 +68,7 @@ func TestConfig_Validate(t *testing.T) {
 				LogOutput: "",
 			},
 			wantErr: true,
-			errMsg:  "invalid log level ''",
+			errMsg:  "'log-level' is required",
 		},
 	}
 
@@ -191,6 +191,23 @@ func TestDefaultConfig(t *testing.T) {
 	}
 }
 
+func TestNewConfig(t *testing.T) {
+	config := NewConfig()
+	if config == DefaultConfig {
+		t.Fatal("NewConfig() returned DefaultConfig instead of a copy")
+	}
+	if *config != *DefaultConfig {
+		t.Errorf("NewConfig() = %+v, want %+v", config, DefaultConfig)
+	}
+
+	if err := config.FlagSet().Parse([]string{"--log-level", "DEBUG"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if DefaultConfig.LogLevel != LevelInfo {
+		t.Errorf("DefaultConfig.LogLevel = %v, want %v", DefaultConfig.LogLevel, LevelInfo)
+	}
+}
+
 func TestConfig_FlagSet_Usage(t *testing.T) {
 	config := &Config{}
 	fs := config.FlagSet()
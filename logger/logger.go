@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -35,17 +36,18 @@ var levels = []string{LevelPanic, LevelFatal, LevelError, LevelWarn, LevelInfo,
 var formats = []string{FormatText, FormatJSON}
 var outputs = []string{OutputStdOut, OutputStdErr}
 
-// Logger wraps zerolog.Logger with configuration.
+// Logger wraps zerolog.Logger with configuration. It's safe for concurrent
+// use, including with Reload.
 type Logger struct {
-	logger zerolog.Logger
+	logger atomic.Pointer[zerolog.Logger]
 	config *Config
 }
 
 // New creates a new Logger instance with the given config.
-// Uses DefaultConfig if config is nil.
+// Uses a copy of DefaultConfig if config is nil.
 func New(config *Config) (*Logger, error) {
 	if config == nil {
-		config = DefaultConfig
+		config = NewConfig()
 	}
 
 	if config.LogLevel == "" {
@@ -67,10 +69,32 @@ func New(config *Config) (*Logger, error) {
 		return nil, err
 	}
 
-	return &Logger{
-		logger: logger,
-		config: config,
-	}, nil
+	l := &Logger{config: config}
+	l.logger.Store(&logger)
+	return l, nil
+}
+
+// Reload rebuilds the logger from its configuration after it changed, e.g.
+// after a config.ConfigLoader it's registered with reloaded the
+// configuration:
+//
+//	loader.Subscribe(func([]config.Change) { _ = log.Reload() })
+//
+// Events already created keep the previous settings. The logger is left as it
+// is when the configuration is invalid.
+func (l *Logger) Reload() error {
+	config := l.config.Clone()
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	logger, err := createZerologLogger(config)
+	if err != nil {
+		return err
+	}
+
+	l.logger.Store(&logger)
+	return nil
 }
 
 // createZerologLogger creates and configures a zerolog.Logger.
@@ -139,7 +163,7 @@ func parseLogLevel(level string) (zerolog.Level, error) {
 
 // GetLogger returns the underlying zerolog.Logger.
 func (l *Logger) GetLogger() zerolog.Logger {
-	return l.logger
+	return *l.logger.Load()
 }
 
 // GetConfig returns the logger configuration.
@@ -149,50 +173,64 @@ func (l *Logger) GetConfig() *Config {
 
 // Panic creates a panic level log event.
 func (l *Logger) Panic() *zerolog.Event {
-	return l.logger.Panic()
+	return l.logger.Load().Panic()
 }
 
 // Fatal creates a fatal level log event.
 func (l *Logger) Fatal() *zerolog.Event {
-	return l.logger.Fatal()
+	return l.logger.Load().Fatal()
 }
 
 // Error creates an error level log event.
 func (l *Logger) Error() *zerolog.Event {
-	return l.logger.Error()
+	return l.logger.Load().Error()
 }
 
 // Warn creates a warning level log event.
 func (l *Logger) Warn() *zerolog.Event {
-	return l.logger.Warn()
+	return l.logger.Load().Warn()
 }
 
 // Info creates an info level log event.
 func (l *Logger) Info() *zerolog.Event {
-	return l.logger.Info()
+	return l.logger.Load().Info()
 }
 
 // Debug creates a debug level log event.
 func (l *Logger) Debug() *zerolog.Event {
-	return l.logger.Debug()
+	return l.logger.Load().Debug()
 }
 
 // Trace creates a trace level log event.
 func (l *Logger) Trace() *zerolog.Event {
-	return l.logger.Trace()
+	return l.logger.Load().Trace()
 }
 
 // Log creates a log event with no specific level.
 func (l *Logger) Log() *zerolog.Event {
-	return l.logger.Log()
+	return l.logger.Load().Log()
 }
 
 // WithLevel creates a log event with the specified level.
 func (l *Logger) WithLevel(level zerolog.Level) *zerolog.Event {
-	return l.logger.WithLevel(level)
+	return l.logger.Load().WithLevel(level)
 }
 
 // With creates a child logger with additional context.
 func (l *Logger) With() zerolog.Context {
-	return l.logger.With()
+	return l.logger.Load().With()
+}
+
+// Deprecation is a deprecated configuration key in use, such as a
+// config.Deprecation.
+type Deprecation interface {
+	// Fields returns the key, its replacement and where it's used as
+	// structured log fields.
+	Fields() map[string]any
+}
+
+// LogDeprecation logs a warning for a deprecated configuration key in use,
+// e.g. from the handler set with config.WithDeprecationHandler.
+func (l *Logger) LogDeprecation(d Deprecation) {
+	l.logger.Load().Warn().Fields(d.Fields()).Msg("deprecated configuration key")
 }
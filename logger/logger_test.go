@@ -47,7 +47,7 @@ func TestNew(t *testing.T) {
 				LogOutput: "stdout",
 			},
 			wantErr: true,
-			errMsg:  "invalid log level",
+			errMsg:  "'log-level' must be one of",
 		},
 		{
 			name: "invalid log format",
@@ -57,7 +57,7 @@ func TestNew(t *testing.T) {
 				LogOutput: "stdout",
 			},
 			wantErr: true,
-			errMsg:  "invalid log format",
+			errMsg:  "'log-format' must be one of",
 		},
 		{
 			name: "invalid log output",
@@ -67,7 +67,7 @@ func TestNew(t *testing.T) {
 				LogOutput: "file",
 			},
 			wantErr: true,
-			errMsg:  "invalid log output",
+			errMsg:  "'log-output' must be one of",
 		},
 	}
 
@@ -197,6 +197,62 @@ func TestParseLogLevel(t *testing.T) {
 	}
 }
 
+func TestNew_NilConfig(t *testing.T) {
+	logger, err := New(nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if logger.GetConfig() == DefaultConfig {
+		t.Error("New(nil) uses DefaultConfig instead of a copy")
+	}
+}
+
+func TestLogger_Reload(t *testing.T) {
+	tests := []struct {
+		name      string
+		logLevel  string
+		wantLevel zerolog.Level
+		wantErr   bool
+	}{
+		{
+			name:      "applies the new level",
+			logLevel:  LevelDebug,
+			wantLevel: zerolog.DebugLevel,
+		},
+		{
+			name:      "invalid level keeps the previous logger",
+			logLevel:  "INVALID",
+			wantLevel: zerolog.InfoLevel,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				LogLevel:  LevelInfo,
+				LogFormat: FormatJSON,
+				LogOutput: OutputStdOut,
+			}
+			logger, err := New(config)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			config.LogLevel = tt.logLevel
+			err = logger.Reload()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := logger.GetLogger().GetLevel(); got != tt.wantLevel {
+				t.Errorf("GetLevel() = %v, want %v", got, tt.wantLevel)
+			}
+		})
+	}
+}
+
 func TestLogger_GetMethods(t *testing.T) {
 	config := &Config{
 		LogLevel:  "INFO",
@@ -228,10 +284,8 @@ func TestLogger_LogMethods(t *testing.T) {
 
 	// Create a logger that writes to our buffer
 	zerologLogger := zerolog.New(&buf).With().Timestamp().Logger()
-	logger := &Logger{
-		logger: zerologLogger,
-		config: DefaultConfig,
-	}
+	logger := &Logger{config: DefaultConfig}
+	logger.logger.Store(&zerologLogger)
 
 	// Test each log method
 	tests := []struct {
@@ -288,10 +342,8 @@ func TestLogger_LogMethods(t *testing.T) {
 func TestLogger_WithLevel(t *testing.T) {
 	var buf bytes.Buffer
 	zerologLogger := zerolog.New(&buf).With().Timestamp().Logger()
-	logger := &Logger{
-		logger: zerologLogger,
-		config: DefaultConfig,
-	}
+	logger := &Logger{config: DefaultConfig}
+	logger.logger.Store(&zerologLogger)
 
 	logger.WithLevel(zerolog.WarnLevel).Msg("test message")
 
@@ -314,10 +366,8 @@ func TestLogger_WithLevel(t *testing.T) {
 func TestLogger_With(t *testing.T) {
 	var buf bytes.Buffer
 	zerologLogger := zerolog.New(&buf).With().Timestamp().Logger()
-	logger := &Logger{
-		logger: zerologLogger,
-		config: DefaultConfig,
-	}
+	logger := &Logger{config: DefaultConfig}
+	logger.logger.Store(&zerologLogger)
 
 	contextLogger := logger.With().Str("component", "test").Logger()
 	contextLogger.Info().Msg("test message")
@@ -341,13 +391,55 @@ func TestLogger_With(t *testing.T) {
 func TestLogger_Log(t *testing.T) {
 	var buf bytes.Buffer
 	zerologLogger := zerolog.New(&buf).With().Timestamp().Logger()
-	logger := &Logger{
-		logger: zerologLogger,
-		config: DefaultConfig,
-	}
+	logger := &Logger{config: DefaultConfig}
+	logger.logger.Store(&zerologLogger)
 
 	event := logger.Log()
 	if event == nil {
 		t.Error("Log() returned nil event")
 	}
 }
+
+// deprecation is a Deprecation with the given fields.
+type deprecation map[string]any
+
+func (d deprecation) Fields() map[string]any {
+	return d
+}
+
+func TestLogger_LogDeprecation(t *testing.T) {
+	var buf bytes.Buffer
+	zerologLogger := zerolog.New(&buf)
+	logger := &Logger{config: DefaultConfig}
+	logger.logger.Store(&zerologLogger)
+
+	logger.LogDeprecation(deprecation{
+		"key":              "session-store-type",
+		"replacement":      "session-store",
+		"source":           "env",
+		"origin":           "APP_SESSION_STORE_TYPE",
+		"deprecated_since": "v1.2.0",
+		"removed_in":       "v2.0.0",
+	})
+
+	var logEntry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &logEntry); err != nil {
+		t.Fatalf("Failed to parse log output: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"level":            "warn",
+		"message":          "deprecated configuration key",
+		"key":              "session-store-type",
+		"replacement":      "session-store",
+		"source":           "env",
+		"origin":           "APP_SESSION_STORE_TYPE",
+		"deprecated_since": "v1.2.0",
+		"removed_in":       "v2.0.0",
+	}
+	for key, value := range want {
+		if logEntry[key] != value {
+			t.Errorf("LogDeprecation() %s = %v, want %v", key, logEntry[key], value)
+		}
+	}
+}